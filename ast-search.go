@@ -11,13 +11,16 @@ import (
 	"flag"
 	"fmt"
 	"go/ast"
+	"go/importer"
 	"go/parser"
 	"go/printer"
 	"go/token"
+	"go/types"
 	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -31,6 +34,20 @@ const (
 	Mutex
 	RWMutex
 	Locker
+	Chan
+	Context
+	AtomicValue
+	AtomicTyped
+	SyncMap
+	Pool
+	ErrGroup
+	Semaphore
+	SingleFlight
+	Timer
+	Ticker
+	TestingT
+	TestingB
+	TestingPB
 	Unknown
 )
 
@@ -48,6 +65,34 @@ func (d DeclType) String() string {
 		return "RWMutex"
 	case Locker:
 		return "Locker"
+	case Chan:
+		return "Chan"
+	case Context:
+		return "Context"
+	case AtomicValue:
+		return "AtomicValue"
+	case AtomicTyped:
+		return "AtomicTyped"
+	case SyncMap:
+		return "SyncMap"
+	case Pool:
+		return "Pool"
+	case ErrGroup:
+		return "ErrGroup"
+	case Semaphore:
+		return "Semaphore"
+	case SingleFlight:
+		return "SingleFlight"
+	case Timer:
+		return "Timer"
+	case Ticker:
+		return "Ticker"
+	case TestingT:
+		return "testing.T"
+	case TestingB:
+		return "testing.B"
+	case TestingPB:
+		return "testing.PB"
 	case Unknown:
 		return "Unknown"
 	default:
@@ -55,18 +100,281 @@ func (d DeclType) String() string {
 	}
 }
 
-type Declaration struct {
-	name   string
+type BufferKind int64
+
+const (
+	Unbuffered BufferKind = iota
+	LiteralBuffer
+	ComputedBuffer
+	UnknownBuffer
+)
+
+func (b BufferKind) String() string {
+	switch b {
+	case Unbuffered:
+		return "unbuffered"
+	case LiteralBuffer:
+		return "literal buffer"
+	case ComputedBuffer:
+		return "computed buffer"
+	case UnknownBuffer:
+		return "unknown buffer"
+	default:
+		panic("Bad buffer kind!")
+	}
+}
+
+// ChanInfo describes a channel declaration or a call to make that
+// creates a channel. The capacity is only known when the channel
+// comes from a call to make.
+type ChanInfo struct {
+	elemType string
+	dir      ast.ChanDir
+	buffer   BufferKind
+	capacity string
+}
+
+func chanDirString(dir ast.ChanDir) string {
+	switch dir {
+	case ast.SEND:
+		return "chan<-"
+	case ast.RECV:
+		return "<-chan"
+	default:
+		return "chan"
+	}
+}
+
+func (c *ChanInfo) String() string {
+	if c.buffer == LiteralBuffer || c.buffer == ComputedBuffer {
+		return fmt.Sprintf("%s %s (%s %s)", chanDirString(c.dir), c.elemType, c.buffer.String(), c.capacity)
+	}
+	return fmt.Sprintf("%s %s (%s)", chanDirString(c.dir), c.elemType, c.buffer.String())
+}
+
+// CollectionKind describes the collection a primitive is declared in,
+// such as the slice in var locks []sync.Mutex.
+type CollectionKind int64
+
+const (
+	NoCollection CollectionKind = iota
+	ArrayCollection
+	SliceCollection
+	MapCollection
+)
+
+func (c CollectionKind) String() string {
+	switch c {
+	case NoCollection:
+		return "none"
+	case ArrayCollection:
+		return "array"
+	case SliceCollection:
+		return "slice"
+	case MapCollection:
+		return "map"
+	default:
+		panic("Bad collection kind!")
+	}
+}
+
+// CondLocker describes the Locker passed to sync.NewCond. A named locker
+// such as &mu or &s.mu is resolved through its target when used, while
+// an anonymous one such as &sync.Mutex{} carries its own type.
+type CondLocker struct {
+	target string
 	typeof DeclType
+	// Whether the locker is the read side of a RWMutex, as returned by
+	// its RLocker method
+	readSide bool
+	// Identifies the locker, so that Conds sharing it can be found
+	key string
+	// The scope of the Cond, which is the whole file for a field
+	scope Scope
+}
+
+// Alias is a name bound to a primitive without declaring a new one: a
+// pointer such as mu := &s.mu, the read side of a RWMutex such as
+// l := m.RLocker(), a method value such as unlock := s.mu.Unlock, or a
+// parameter bound to an argument of a call in the file.
+type Alias struct {
+	target string
+	// The method of target, for a method value
+	method string
+	// Whether the alias is the Locker returned by the RLocker method of
+	// target
+	readSide bool
+	// The scope of the alias, and the position where target is looked up
+	scope Scope
+	pos   token.Pos
+}
+
+// AliasFunc is a function, or a function literal assigned to a name,
+// whose parameters are bound to the arguments of its calls.
+type AliasFunc struct {
+	params []string
+	// The scope of the name of the function, and of its parameters
+	scope      Scope
+	paramScope Scope
+}
+
+// AliasCall is a call of a function by name, with the aliases passed as
+// its arguments. An argument that is not an alias has an empty target.
+type AliasCall struct {
+	fun  string
+	args []Alias
+	pos  token.Pos
+}
+
+// The methods whose method values are tracked as aliases
+var aliasMethods = map[string]bool{"Lock": true, "Unlock": true, "RLock": true, "RUnlock": true,
+	"TryLock": true, "TryRLock": true, "Wait": true, "Done": true, "Add": true, "Signal": true,
+	"Broadcast": true, "Do": true}
+
+// The maximum length of a chain of aliases, such as a parameter bound to
+// another parameter
+const maxAliasDepth = 8
+
+// Scope is the range of positions in which a declaration is visible.
+// The zero Scope is the whole file.
+type Scope struct {
+	pos token.Pos
+	end token.Pos
+}
+
+func (s Scope) contains(pos token.Pos) bool {
+	return s.end == token.NoPos || (s.pos <= pos && pos < s.end)
+}
+
+type Declaration struct {
+	name       string
+	typeof     DeclType
+	chanInfo   *ChanInfo
+	collection CollectionKind
+	scope      Scope
+	// Whether the declaration is a struct field, which is only reached
+	// through a selector such as s.mu
+	field bool
+	// Whether the declaration is the read side of a RWMutex, reached
+	// through an alias of its RLocker method
+	readSide bool
+}
+
+// LocalVar is a local variable or parameter, used to resolve the
+// identifiers referring to loop variables.
+type LocalVar struct {
+	scope Scope
+	// The position of the declaring identifier
+	pos     token.Pos
+	loopVar bool
+	// Whether the type of the variable is known from its declaration,
+	// from an explicit type or a value such as a composite literal
+	typed bool
+}
+
+// TickerTarget is a name, such as t or s.ticker, that the result of
+// time.NewTicker is assigned to at pos.
+type TickerTarget struct {
+	target string
+	pos    token.Pos
+}
+
+// ChanAssign is a channel created by make and assigned at pos to a
+// target declared elsewhere, such as s.sigs.
+type ChanAssign struct {
+	target string
+	pos    token.Pos
+	info   *ChanInfo
+}
+
+// TypedVar is a variable, field or parameter of a named type, which may
+// embed primitives.
+type TypedVar struct {
+	typeName string
+	scope    Scope
+	field    bool
 }
 
 func createDecl(target string, typeof DeclType) Declaration {
 	return Declaration{name: target, typeof: typeof}
 }
 
+func createChanDecl(target string, info *ChanInfo) Declaration {
+	return Declaration{name: target, typeof: Chan, chanInfo: info}
+}
+
+// Options holds the command line options that change how files are
+// analyzed, along with the importer used when type checking.
+type Options struct {
+	// Whether the primitives declared in a generic type are counted
+	// again for each distinct instantiation of the type
+	perInstantiation bool
+	// Whether calls are classified by the type of their receiver, found
+	// by type checking the package of each file
+	typeCheck bool
+	// The importer used to type check, shared by the packages of a run
+	// so that imported packages are type checked once
+	importer *offlineImporter
+}
+
 type AnalysisState struct {
-	decls  map[string][]Declaration
-	counts Counts
+	decls map[string][]Declaration
+	// The import path for each package name in the file, and the
+	// import paths of dot imports
+	imports    map[string]string
+	dotImports []string
+	// The primitives and named types embedded in each struct type, and
+	// the named types of variables, fields and parameters, used to
+	// attribute promoted method calls
+	embeds        map[string][]DeclType
+	embeddedTypes map[string][]string
+	typedVars     map[string][]TypedVar
+	// The targets tickers created by time.NewTicker are assigned to, and
+	// the declarations of the tickers that are stopped
+	tickers        []TickerTarget
+	stoppedTickers map[Declaration]bool
+	// The channels created by make and assigned to fields or existing
+	// variables
+	chanAssigns []ChanAssign
+	// The go directive of the nearest go.mod, or "" if there is none
+	goVersion string
+	// Whether the file is a _test.go file
+	testFile bool
+	// The primitives declared in each generic type, and the distinct
+	// instantiations of generic types found in the file
+	generics       map[string][]Declaration
+	instantiations map[string]bool
+	// The lockers passed to sync.NewCond for each Cond
+	condLockers map[string][]CondLocker
+	// The type information of the package of the file, or nil if it was
+	// not type checked
+	info *types.Info
+	// The local variables and parameters of the file
+	locals map[string][]LocalVar
+	// The aliases of primitives, the functions whose parameters are bound
+	// to arguments, and the calls binding them
+	aliases    map[string][]Alias
+	aliasFuncs map[string][]AliasFunc
+	aliasCalls []AliasCall
+	// Whether the parameters were bound to arguments, after which names
+	// are also looked up through their aliases
+	aliasesResolved bool
+	// The method values assigned to aliases, which are counted where the
+	// alias is called instead
+	methodValues map[*ast.SelectorExpr]bool
+	// The package-level declarations of the packages imported from the
+	// module, by directory, shared by the files of the package
+	modulePackages map[string]PackageDecls
+	// The selectors called as methods in the file, which leaves out
+	// package functions and fields that are not called
+	methodCalls map[*ast.SelectorExpr]bool
+	// The package-level declarations of all the files of the package,
+	// shared read-only by their states, or nil for the package itself
+	pkg *AnalysisState
+	// The position of the node being visited, where names are looked up
+	pos     token.Pos
+	options Options
+	counts  Counts
 }
 
 type Counts struct {
@@ -76,6 +384,24 @@ type Counts struct {
 	mutexDecls       int
 	rwMutexDecls     int
 	lockerDecls      int
+	contextDecls     int
+	atomicValueDecls int
+	atomicTypedDecls int
+	syncMapDecls     int
+	poolDecls        int
+	errGroupDecls    int
+	semaphoreDecls   int
+	singleFlightDecl int
+	timerDecls       int
+	tickerDecls      int
+	chanDecls        int
+	bidiChanDecls    int
+	sendChanDecls    int
+	recvChanDecls    int
+	chanMake         int
+	unbufferedMake   int
+	literalBufMake   int
+	computedBufMake  int
 	waitGroupDone    int
 	waitGroupAdd     int
 	waitGroupWait    int
@@ -83,6 +409,12 @@ type Counts struct {
 	mutexUnlock      int
 	rwMutexLock      int
 	rwMutexUnlock    int
+	rwMutexRLock     int
+	rwMutexRUnlock   int
+	rwMutexRLocker   int
+	rwMutexTryLock   int
+	rwMutexTryRLock  int
+	mutexTryLock     int
 	lockerLock       int
 	lockerUnlock     int
 	condLock         int
@@ -92,36 +424,211 @@ type Counts struct {
 	condBroadcast    int
 	condNew          int
 	onceDo           int
+	onceFunc         int
+	onceValue        int
+	onceValues       int
+	waitGroupGo      int
+	legacyOnceValue  int
+	legacyWgGo       int
+	chanSend         int
+	chanRecv         int
+	chanRecvOk       int
+	chanRange        int
+	chanClose        int
+	goStmts          int
+	goNamedFunc      int
+	goMethod         int
+	goClosure        int
+	goOther          int
+	goInLoop         int
+	goLoopVarArg     int
+	goLoopVarCapture int
+	atomicFuncAdd    int
+	atomicFuncLoad   int
+	atomicFuncStore  int
+	atomicFuncSwap   int
+	atomicFuncCAS    int
+	atomicValueLoad  int
+	atomicValueStore int
+	atomicValueSwap  int
+	atomicValueCAS   int
+	atomicTypedLoad  int
+	atomicTypedStore int
+	atomicTypedAdd   int
+	atomicTypedSwap  int
+	atomicTypedCAS   int
+	syncMapLoad      int
+	syncMapStore     int
+	syncMapLoadStore int
+	syncMapLoadDel   int
+	syncMapDelete    int
+	syncMapRange     int
+	syncMapSwap      int
+	syncMapCAS       int
+	poolGet          int
+	poolPut          int
+	poolNew          int
+	errGroupGo       int
+	errGroupTryGo    int
+	errGroupWait     int
+	errGroupSetLimit int
+	errGroupWithCtx  int
+	semAcquire       int
+	semTryAcquire    int
+	semRelease       int
+	singleFlightDo   int
+	singleFlightChan int
+	singleFlightFgt  int
+	timeAfter        int
+	timeTick         int
+	timeNewTimer     int
+	timeNewTicker    int
+	timeAfterFunc    int
+	timerStop        int
+	timerReset       int
+	tickerStop       int
+	tickerReset      int
+	tickerNotStopped int
+	timeAfterInLoop  int
+	rtGosched        int
+	rtGOMAXPROCS     int
+	rtLockOSThread   int
+	rtUnlockOSThread int
+	rtNumGoroutine   int
+	rtGoexit         int
+	dbgSetMaxThreads int
+	testParallel     int
+	benchRunParallel int
+	benchSetParallel int
+	pbNext           int
+	goInTest         int
+	goInBenchmark    int
+	signalNotify     int
+	signalStop       int
+	signalIgnore     int
+	signalNotifyCtx  int
+	signalUnbuffered int
+	genericTypes     int
+	genericInsts     int
+	condLockers      int
+	condSharedLocker int
+	primArrayDecls   int
+	primSliceDecls   int
+	primMapDecls     int
+	indexedCalls     int
+	typedCalls       int
+	typeFallbacks    int
+	primAliases      int
+	aliasedUses      int
+	ctxWithCancel    int
+	ctxWithTimeout   int
+	ctxWithDeadline  int
+	ctxWithValue     int
+	ctxWithCause     int
+	ctxDone          int
+	ctxErr           int
+	selectStmts      int
+	selectCases      int
+	selectSendCases  int
+	selectRecvCases  int
+	selectDefault    int
+	selectTimerCases int
+	selectEmpty      int
+	selectSingleCase int
+	selectInLoop     int
 	unknownDone      int
 	unknownAdd       int
 	unknownWait      int
 	unknownLock      int
 	unknownUnlock    int
+	unknownRLock     int
+	unknownRUnlock   int
+	unknownRLocker   int
+	unknownTryLock   int
+	unknownTryRLock  int
 	unknownSignal    int
 	unknownBroadcast int
 	unknownDo        int
+	unknownLoad      int
+	unknownStore     int
+	unknownSwap      int
+	unknownCAS       int
+	unknownLoadStore int
+	unknownLoadDel   int
+	unknownDelete    int
+	unknownRange     int
+	unknownGet       int
+	unknownPut       int
+	unknownGo        int
+	unknownTryGo     int
+	unknownSetLimit  int
+	unknownAcquire   int
+	unknownTryAcq    int
+	unknownRelease   int
+	unknownDoChan    int
+	unknownForget    int
+	unknownStop      int
+	unknownReset     int
+	unknownSend      int
+	unknownRecv      int
+	unknownClose     int
 }
 
 func (s *AnalysisState) addDecl(declaration Declaration) {
-	_, ok := s.decls[declaration.name]
-	if ok {
-		foundDecl := false
-		for _, d := range s.decls[declaration.name] {
-			if d.typeof == declaration.typeof {
-				fmt.Printf("Found declaration for name %s and type %s\n", declaration.name, declaration.typeof.String())
-				foundDecl = true
-			}
-		}
-		if !foundDecl {
-			fmt.Printf("Adding declaration for name %s and type %s\n", declaration.name, declaration.typeof.String())
-			s.decls[declaration.name] = append(s.decls[declaration.name], declaration)
-		}
+	if containsDecl(s.decls[declaration.name], declaration) {
+		fmt.Printf("Found declaration for name %s and type %s\n", declaration.name, declaration.typeof.String())
 	} else {
 		fmt.Printf("Adding declaration for name %s and type %s\n", declaration.name, declaration.typeof.String())
 		s.decls[declaration.name] = append(s.decls[declaration.name], declaration)
 	}
 }
 
+// containsDecl reports whether vs has a declaration with the type,
+// scope and kind of declaration.
+func containsDecl(vs []Declaration, declaration Declaration) bool {
+	for _, d := range vs {
+		if d.typeof == declaration.typeof && d.scope == declaration.scope && d.field == declaration.field {
+			return true
+		}
+	}
+	return false
+}
+
+// declsOf returns the declarations of name in the file, followed by the
+// package-level ones of the other files of the package.
+func (s *AnalysisState) declsOf(name string) []Declaration {
+	vs := s.decls[name]
+	if s.pkg == nil || len(s.pkg.decls[name]) == 0 {
+		return vs
+	}
+	res := append([]Declaration{}, vs...)
+	for _, d := range s.pkg.decls[name] {
+		if !containsDecl(vs, d) {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// typedVarsOf returns the typed variables, fields and parameters named
+// name in the file and in the package.
+func (s *AnalysisState) typedVarsOf(name string) []TypedVar {
+	if s.pkg == nil {
+		return s.typedVars[name]
+	}
+	return append(append([]TypedVar{}, s.typedVars[name]...), s.pkg.typedVars[name]...)
+}
+
+// genericsOf returns the primitives declared in the generic type
+// typeName, preferring the file's own declaration.
+func (s *AnalysisState) genericsOf(typeName string) ([]Declaration, bool) {
+	prims, ok := s.generics[typeName]
+	if !ok && s.pkg != nil {
+		prims, ok = s.pkg.generics[typeName]
+	}
+	return prims, ok
+}
+
 func (s *AnalysisState) addWaitGroupDecl() {
 	s.counts.waitGroupDecls++
 }
@@ -146,6 +653,70 @@ func (s *AnalysisState) addLockerDecl() {
 	s.counts.lockerDecls++
 }
 
+func (s *AnalysisState) addContextDecl() {
+	s.counts.contextDecls++
+}
+
+func (s *AnalysisState) addAtomicValueDecl() {
+	s.counts.atomicValueDecls++
+}
+
+func (s *AnalysisState) addAtomicTypedDecl() {
+	s.counts.atomicTypedDecls++
+}
+
+func (s *AnalysisState) addSyncMapDecl() {
+	s.counts.syncMapDecls++
+}
+
+func (s *AnalysisState) addPoolDecl() {
+	s.counts.poolDecls++
+}
+
+func (s *AnalysisState) addErrGroupDecl() {
+	s.counts.errGroupDecls++
+}
+
+func (s *AnalysisState) addSemaphoreDecl() {
+	s.counts.semaphoreDecls++
+}
+
+func (s *AnalysisState) addSingleFlightDecl() {
+	s.counts.singleFlightDecl++
+}
+
+func (s *AnalysisState) addTimerDecl() {
+	s.counts.timerDecls++
+}
+
+func (s *AnalysisState) addTickerDecl() {
+	s.counts.tickerDecls++
+}
+
+func (s *AnalysisState) addChanDecl(dir ast.ChanDir) {
+	s.counts.chanDecls++
+	switch dir {
+	case ast.SEND:
+		s.counts.sendChanDecls++
+	case ast.RECV:
+		s.counts.recvChanDecls++
+	default:
+		s.counts.bidiChanDecls++
+	}
+}
+
+func (s *AnalysisState) addChanMake(buffer BufferKind) {
+	s.counts.chanMake++
+	switch buffer {
+	case Unbuffered:
+		s.counts.unbufferedMake++
+	case LiteralBuffer:
+		s.counts.literalBufMake++
+	case ComputedBuffer:
+		s.counts.computedBufMake++
+	}
+}
+
 func (s *AnalysisState) addWaitGroupDone() {
 	s.counts.waitGroupDone++
 }
@@ -174,6 +745,30 @@ func (s *AnalysisState) addRWMutexUnlock() {
 	s.counts.rwMutexUnlock++
 }
 
+func (s *AnalysisState) addRWMutexRLock() {
+	s.counts.rwMutexRLock++
+}
+
+func (s *AnalysisState) addRWMutexRUnlock() {
+	s.counts.rwMutexRUnlock++
+}
+
+func (s *AnalysisState) addRWMutexRLocker() {
+	s.counts.rwMutexRLocker++
+}
+
+func (s *AnalysisState) addRWMutexTryLock() {
+	s.counts.rwMutexTryLock++
+}
+
+func (s *AnalysisState) addRWMutexTryRLock() {
+	s.counts.rwMutexTryRLock++
+}
+
+func (s *AnalysisState) addMutexTryLock() {
+	s.counts.mutexTryLock++
+}
+
 func (s *AnalysisState) addLockerLock() {
 	s.counts.lockerLock++
 }
@@ -210,723 +805,6015 @@ func (s *AnalysisState) addOnceDo() {
 	s.counts.onceDo++
 }
 
-func (s *AnalysisState) addUnknownDone() {
-	s.counts.unknownDone++
+func (s *AnalysisState) addChanSend() {
+	s.counts.chanSend++
 }
 
-func (s *AnalysisState) addUnknownAdd() {
-	s.counts.unknownAdd++
+func (s *AnalysisState) addChanRecv() {
+	s.counts.chanRecv++
 }
 
-func (s *AnalysisState) addUnknownWait() {
-	s.counts.unknownWait++
+func (s *AnalysisState) addChanRecvOk() {
+	s.counts.chanRecvOk++
 }
 
-func (s *AnalysisState) addUnknownLock() {
-	s.counts.unknownLock++
+func (s *AnalysisState) addChanRange() {
+	s.counts.chanRange++
 }
 
-func (s *AnalysisState) addUnknownUnlock() {
-	s.counts.unknownUnlock++
+func (s *AnalysisState) addChanClose() {
+	s.counts.chanClose++
 }
 
-func (s *AnalysisState) addUnknownSignal() {
-	s.counts.unknownSignal++
+func (s *AnalysisState) addImport(name string, path string) {
+	fmt.Printf("Adding import of %s as %s\n", path, name)
+	s.imports[name] = path
 }
 
-func (s *AnalysisState) addUnknownBroadcast() {
-	s.counts.unknownBroadcast++
+func (s *AnalysisState) addEmbed(typeName string, typeof DeclType) {
+	fmt.Printf("Adding embedded %s for type %s\n", typeof.String(), typeName)
+	s.embeds[typeName] = append(s.embeds[typeName], typeof)
 }
 
-func (s *AnalysisState) addUnknownDo() {
-	s.counts.unknownDo++
+func (s *AnalysisState) addEmbeddedType(typeName string, embedded string) {
+	s.embeddedTypes[typeName] = append(s.embeddedTypes[typeName], embedded)
 }
 
-func splitTarget(target string) string {
-	parts := strings.Split(target, ".")
-	return parts[len(parts)-1]
+func (s *AnalysisState) addTypedVar(name string, typedVar TypedVar) {
+	s.typedVars[name] = append(s.typedVars[name], typedVar)
 }
 
-func targetPieces(target string) int {
-	parts := strings.Split(target, ".")
-	return len(parts)
+// lookup returns the declarations of target visible at the current
+// position, or the declaration of the primitive it refers to if it is
+// an alias.
+func (s *AnalysisState) lookup(target string) ([]Declaration, bool) {
+	d, ok := s.lookupThroughAlias(target, 0)
+	if ok {
+		fmt.Printf("Found %s %s through alias %s\n", d.typeof.String(), d.name, target)
+		s.addAliasedUses()
+		return []Declaration{d}, true
+	}
+	return s.lookupDecls(target)
 }
 
-func stateHeaders() []string {
-	res := []string{"fileName", "waitGroupDecls", "condDecls", "onceDecls",
-		"mutexDecls", "rwMutexDecls", "lockerDecls", "waitGroupDone",
-		"waitGroupAdd", "waitGroupWait", "mutexLock", "mutexUnlock",
-		"rwMutexLock", "rwMutexUnlock", "lockerLock", "lockerUnlock",
-		"condLock", "condUnlock",
-		"condWait", "condSignal", "condBroadcast", "condNew",
-		"onceDo", "unknownDone", "unknownAdd", "unknownWait",
-		"unknownLock", "unknownUnlock", "unknownSignal", "unknownBroadcast",
-		"unknownDo",
+// lookupDecls returns the declarations of the last part of target that
+// are visible at the current position. Only those of the innermost
+// scope are returned, so a local mu shadows a global one. A plain name
+// never refers to a struct field, while a target with several parts,
+// such as s.mu, prefers struct fields over variables and parameters.
+func (s *AnalysisState) lookupDecls(target string) ([]Declaration, bool) {
+	selector := targetPieces(target) > 1
+	var preferred, others []Declaration
+	for _, d := range s.declsOf(splitTarget(target)) {
+		if !d.scope.contains(s.pos) {
+			continue
+		}
+		if d.field == selector {
+			preferred = append(preferred, d)
+		} else if selector {
+			others = append(others, d)
+		}
 	}
-	return res
+	if len(preferred) == 0 {
+		preferred = others
+	}
+	var res []Declaration
+	for _, d := range preferred {
+		if len(res) > 0 && d.scope.pos > res[0].scope.pos {
+			res = nil
+		}
+		if len(res) == 0 || d.scope.pos == res[0].scope.pos {
+			res = append(res, d)
+		}
+	}
+	return res, len(res) > 0
 }
 
-func (s *AnalysisState) stateToSlice(fileName string) []string {
-	res := []string{fileName, strconv.Itoa(s.counts.waitGroupDecls),
-		strconv.Itoa(s.counts.condDecls), strconv.Itoa(s.counts.onceDecls),
-		strconv.Itoa(s.counts.mutexDecls), strconv.Itoa(s.counts.rwMutexDecls),
-		strconv.Itoa(s.counts.lockerDecls), strconv.Itoa(s.counts.waitGroupDone),
-		strconv.Itoa(s.counts.waitGroupAdd), strconv.Itoa(s.counts.waitGroupWait),
-		strconv.Itoa(s.counts.mutexLock), strconv.Itoa(s.counts.mutexUnlock),
-		strconv.Itoa(s.counts.rwMutexLock), strconv.Itoa(s.counts.rwMutexUnlock),
-		strconv.Itoa(s.counts.lockerLock), strconv.Itoa(s.counts.lockerUnlock),
-		strconv.Itoa(s.counts.condLock), strconv.Itoa(s.counts.condUnlock),
-		strconv.Itoa(s.counts.condWait), strconv.Itoa(s.counts.condSignal),
-		strconv.Itoa(s.counts.condBroadcast), strconv.Itoa(s.counts.condNew),
-		strconv.Itoa(s.counts.onceDo), strconv.Itoa(s.counts.unknownDone),
-		strconv.Itoa(s.counts.unknownAdd), strconv.Itoa(s.counts.unknownWait),
-		strconv.Itoa(s.counts.unknownLock), strconv.Itoa(s.counts.unknownUnlock),
-		strconv.Itoa(s.counts.unknownSignal), strconv.Itoa(s.counts.unknownBroadcast),
-		strconv.Itoa(s.counts.unknownDo),
+func (s *AnalysisState) addLocal(name string, local LocalVar) {
+	for _, l := range s.locals[name] {
+		if l.pos == local.pos {
+			return
+		}
 	}
-	return res
+	s.locals[name] = append(s.locals[name], local)
 }
 
-func (s *AnalysisState) addDone(target string) {
-	target = splitTarget(target)
-	vs, ok := s.decls[target]
-	if ok {
-		if len(vs) == 1 {
-			if vs[0].typeof == WaitGroup {
-				fmt.Printf("Found use of Done for WaitGroup target %s\n", vs[0].name)
-				s.addWaitGroupDone()
-			} else {
-				fmt.Printf("Unexpected match for target %s for call to Done\n", target)
-				s.addUnknownDone()
-			}
-		} else {
-			fmt.Printf("Multiple matches for target %s for call to Done\n", target)
-			s.addUnknownDone()
+// resolveLocal returns the local variable or parameter the identifier
+// name at pos refers to: the last one declared before pos in the
+// innermost scope containing pos.
+func (s *AnalysisState) resolveLocal(name string, pos token.Pos) (LocalVar, bool) {
+	var res LocalVar
+	found := false
+	for _, l := range s.locals[name] {
+		if !l.scope.contains(pos) || l.pos > pos {
+			continue
+		}
+		if !found || l.scope.pos > res.scope.pos || (l.scope.pos == res.scope.pos && l.pos > res.pos) {
+			res = l
+			found = true
 		}
-	} else {
-		fmt.Printf("No match for target %s for call to Done\n", target)
-		s.addUnknownDone()
 	}
+	return res, found
 }
 
-func (s *AnalysisState) addAdd(target string) {
-	target = splitTarget(target)
-	vs, ok := s.decls[target]
-	if ok {
-		if len(vs) == 1 {
-			if vs[0].typeof == WaitGroup {
-				fmt.Printf("Found use of Add for WaitGroup target %s\n", vs[0].name)
-				s.addWaitGroupAdd()
-			} else {
-				fmt.Printf("Unexpected match for target %s for call to Add\n", target)
-				s.addUnknownAdd()
-			}
-		} else {
-			fmt.Printf("Multiple matches for target %s for call to Add\n", target)
-			s.addUnknownAdd()
+// couldBePrimitive checks if target, which matches no declaration of a
+// primitive, could still be one, so that a call on it is counted as
+// unknown. It cannot be one if it is a local variable or parameter of a
+// known type, or a variable, field or parameter of a named type.
+func (s *AnalysisState) couldBePrimitive(target string) bool {
+	name := splitTarget(target)
+	selector := targetPieces(target) > 1
+	if !selector {
+		l, ok := s.resolveLocal(name, s.pos)
+		if ok && l.typed {
+			return false
+		}
+	}
+	for _, tv := range s.typedVarsOf(name) {
+		if tv.scope.contains(s.pos) && tv.field == selector {
+			return false
 		}
-	} else {
-		fmt.Printf("No match for target %s for call to Add\n", target)
-		s.addUnknownAdd()
 	}
+	return true
 }
 
-func (s *AnalysisState) addWait(target string) {
-	target = splitTarget(target)
-	vs, ok := s.decls[target]
-	if ok {
-		if len(vs) == 1 {
-			if vs[0].typeof == WaitGroup {
-				fmt.Printf("Found use of Wait for WaitGroup target %s\n", vs[0].name)
-				s.addWaitGroupWait()
-			} else if vs[0].typeof == Cond {
-				fmt.Printf("Found use of Wait for Cond target %s\n", vs[0].name)
-				s.addCondWait()
-			} else {
-				fmt.Printf("Unexpected match for target %s for call to Wait\n", target)
-				s.addUnknownWait()
-			}
-		} else {
-			fmt.Printf("Multiple matches for target %s for call to Wait\n", target)
-			s.addUnknownWait()
+func (s *AnalysisState) addAlias(name string, alias Alias) {
+	s.aliases[name] = append(s.aliases[name], alias)
+}
+
+func (s *AnalysisState) addAliasFunc(name string, f AliasFunc) {
+	s.aliasFuncs[name] = append(s.aliasFuncs[name], f)
+}
+
+// visibleAliases returns the aliases of name visible at the current
+// position, from the innermost scope.
+func (s *AnalysisState) visibleAliases(name string) []Alias {
+	var res []Alias
+	for _, a := range s.aliases[name] {
+		if !a.scope.contains(s.pos) {
+			continue
+		}
+		if len(res) > 0 && a.scope.pos > res[0].scope.pos {
+			res = nil
+		}
+		if len(res) == 0 || a.scope.pos == res[0].scope.pos {
+			res = append(res, a)
 		}
-	} else {
-		fmt.Printf("No match for target %s for call to Wait\n", target)
-		s.addUnknownWait()
 	}
+	return res
 }
 
-func (s *AnalysisState) addLock(target string) {
-	_, ok := s.decls[target]
-	if !ok && targetPieces(target) > 1 && splitTarget(target) == "L" {
-		target = target[:len(target)-2]
+// lookupThroughAlias resolves the plain name target through its aliases
+// to the declaration of the primitive they refer to. It is used if the
+// name has no declaration of its own, if it is declared as a Locker or
+// if the aliases are in an inner scope. All the aliases must refer to
+// the same kind of primitive, as they do when every call of a function
+// passes the same primitive to a parameter.
+func (s *AnalysisState) lookupThroughAlias(target string, depth int) (Declaration, bool) {
+	if !s.aliasesResolved || depth > maxAliasDepth || targetPieces(target) > 1 {
+		return Declaration{}, false
 	}
-	target = splitTarget(target)
-	vs, ok := s.decls[target]
-	if ok {
-		if len(vs) == 1 {
-			if vs[0].typeof == Cond {
-				fmt.Printf("Found use of Lock for Cond target %s\n", vs[0].name)
-				s.addCondLock()
-			} else if vs[0].typeof == Mutex {
-				fmt.Printf("Found use of Lock for Mutex target %s\n", vs[0].name)
-				s.addMutexLock()
-			} else if vs[0].typeof == RWMutex {
-				fmt.Printf("Found use of Lock for RWMutex target %s\n", vs[0].name)
-				s.addRWMutexLock()
-			} else if vs[0].typeof == Locker {
-				fmt.Printf("Found use of Lock for Locker target %s\n", vs[0].name)
-				s.addLockerLock()
-			} else {
-				fmt.Printf("Unexpected match for target %s for call to Lock\n", target)
-				s.addUnknownLock()
-			}
-		} else {
-			fmt.Printf("Multiple matches for target %s for call to Lock\n", target)
-			s.addUnknownLock()
+	aliases := s.visibleAliases(target)
+	if len(aliases) == 0 {
+		return Declaration{}, false
+	}
+	vs, _ := s.lookupDecls(target)
+	if len(vs) > 1 || (len(vs) == 1 && vs[0].typeof != Locker && vs[0].scope.pos >= aliases[0].scope.pos) {
+		return Declaration{}, false
+	}
+	pos := s.pos
+	defer func() { s.pos = pos }()
+	var res Declaration
+	for i, a := range aliases {
+		s.pos = a.pos
+		d, ok := s.resolveAliasTarget(a.target, depth+1)
+		if !ok {
+			return Declaration{}, false
 		}
-	} else {
-		fmt.Printf("No match for target %s for call to Lock\n", target)
-		s.addUnknownLock()
+		d.readSide = d.readSide || a.readSide
+		if i > 0 && (d.typeof != res.typeof || d.readSide != res.readSide) {
+			fmt.Printf("Conflicting aliases for target %s\n", target)
+			return Declaration{}, false
+		}
+		res = d
+	}
+	if res.typeof == Locker && len(vs) == 1 {
+		// Another Locker says no more than the declaration itself
+		return Declaration{}, false
 	}
+	return res, true
 }
 
-func (s *AnalysisState) addUnlock(target string) {
-	_, ok := s.decls[target]
-	if !ok && targetPieces(target) > 1 && splitTarget(target) == "L" {
-		target = target[:len(target)-2]
-	}
-	target = splitTarget(target)
-	vs, ok := s.decls[target]
+// resolveAliasTarget returns the single declaration of the primitive
+// that target refers to, either directly or through its aliases.
+func (s *AnalysisState) resolveAliasTarget(target string, depth int) (Declaration, bool) {
+	d, ok := s.lookupThroughAlias(target, depth)
 	if ok {
-		if len(vs) == 1 {
-			if vs[0].typeof == Cond {
-				fmt.Printf("Found use of Unlock for Cond target %s\n", vs[0].name)
-				s.addCondUnlock()
-			} else if vs[0].typeof == Mutex {
-				fmt.Printf("Found use of Unlock for Mutex target %s\n", vs[0].name)
-				s.addMutexUnlock()
-			} else if vs[0].typeof == RWMutex {
-				fmt.Printf("Found use of Unlock for RWMutex target %s\n", vs[0].name)
-				s.addRWMutexUnlock()
-			} else if vs[0].typeof == Locker {
-				fmt.Printf("Found use of Unlock for Locker target %s\n", vs[0].name)
-				s.addLockerUnlock()
-			} else {
-				fmt.Printf("Unexpected match for target %s for call to Unlock\n", target)
-				s.addUnknownUnlock()
-			}
-		} else {
-			fmt.Printf("Multiple matches for target %s for call to Unlock\n", target)
-			s.addUnknownUnlock()
-		}
-	} else {
-		fmt.Printf("No match for target %s for call to Unlock\n", target)
-		s.addUnknownUnlock()
+		return d, true
+	}
+	vs, ok := s.lookupDecls(target)
+	if !ok || len(vs) != 1 || vs[0].typeof == Unknown {
+		return Declaration{}, false
 	}
+	return vs[0], true
 }
 
-func (s *AnalysisState) addSignal(target string) {
-	target = splitTarget(target)
-	vs, ok := s.decls[target]
-	if ok {
-		if len(vs) == 1 {
-			if vs[0].typeof == Cond {
-				fmt.Printf("Found use of Signal for Cond target %s\n", vs[0].name)
-				s.addCondSignal()
-			} else {
-				fmt.Printf("Unexpected match for target %s for call to Signal\n", target)
-				s.addUnknownSignal()
-			}
-		} else {
-			fmt.Printf("Multiple matches for target %s for call to Signal\n", target)
-			s.addUnknownSignal()
+// aliasMethod returns the method of the method values name is bound to
+// at the current position, or "" if it is not a method value.
+func (s *AnalysisState) aliasMethod(name string) string {
+	aliases := s.visibleAliases(name)
+	if len(aliases) == 0 {
+		return ""
+	}
+	for _, a := range aliases[1:] {
+		if a.method != aliases[0].method {
+			return ""
 		}
-	} else {
-		fmt.Printf("No match for target %s for call to Signal\n", target)
-		s.addUnknownSignal()
 	}
+	return aliases[0].method
 }
 
-func (s *AnalysisState) addBroadcast(target string) {
+// resolveAliases binds the parameters of the functions called by name
+// in the file to the aliases passed as arguments, and counts the
+// aliases that refer to a primitive.
+func (s *AnalysisState) resolveAliases() {
+	for _, call := range s.aliasCalls {
+		var funcs []AliasFunc
+		for _, f := range s.aliasFuncs[call.fun] {
+			if !f.scope.contains(call.pos) {
+				continue
+			}
+			if len(funcs) > 0 && f.scope.pos > funcs[0].scope.pos {
+				funcs = nil
+			}
+			if len(funcs) == 0 || f.scope.pos == funcs[0].scope.pos {
+				funcs = append(funcs, f)
+			}
+		}
+		if len(funcs) != 1 || len(funcs[0].params) != len(call.args) {
+			continue
+		}
+		for i, param := range funcs[0].params {
+			a := call.args[i]
+			if param == "" || param == "_" || a.target == "" {
+				continue
+			}
+			fmt.Printf("Binding parameter %s of %s to %s\n", param, call.fun, a.target)
+			a.scope = funcs[0].paramScope
+			s.addAlias(param, a)
+		}
+	}
+	s.aliasesResolved = true
+
+	var names []string
+	for name := range s.aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pos := s.pos
+	for _, name := range names {
+		for _, a := range s.aliases[name] {
+			s.pos = a.pos
+			d, ok := s.resolveAliasTarget(a.target, 1)
+			if ok {
+				fmt.Printf("Found alias %s of %s %s\n", name, d.typeof.String(), d.name)
+				s.addPrimAliases()
+			}
+		}
+	}
+	s.pos = pos
+}
+
+// embeddedPrimitives returns the primitives embedded in the struct type
+// typeName, including those promoted through embedded struct types.
+func (s *AnalysisState) embeddedPrimitives(typeName string, seen map[string]bool) []DeclType {
+	if seen[typeName] {
+		return nil
+	}
+	seen[typeName] = true
+	res := append([]DeclType{}, s.embeds[typeName]...)
+	embeddedTypes := s.embeddedTypes[typeName]
+	if s.pkg != nil {
+		res = append(res, s.pkg.embeds[typeName]...)
+		embeddedTypes = append(append([]string{}, embeddedTypes...), s.pkg.embeddedTypes[typeName]...)
+	}
+	for _, embedded := range embeddedTypes {
+		res = append(res, s.embeddedPrimitives(embedded, seen)...)
+	}
+	return res
+}
+
+// resolveEmbeddedDecls adds a declaration for each variable, field or
+// parameter whose type embeds a primitive, so that promoted method
+// calls such as c.Lock() can be attributed to the embedded primitive.
+// The primitive itself was already counted where it is embedded.
+func (s *AnalysisState) resolveEmbeddedDecls() {
+	for name, typedVars := range s.typedVars {
+		for _, tv := range typedVars {
+			for _, typeof := range s.embeddedPrimitives(tv.typeName, map[string]bool{}) {
+				fmt.Printf("Found %s with embedded %s through type %s\n", name, typeof.String(), tv.typeName)
+				d := createDecl(name, typeof)
+				d.scope = tv.scope
+				d.field = tv.field
+				s.addDecl(d)
+			}
+		}
+	}
+}
+
+// PackageDecls holds the package-level declarations of a file, which
+// are visible in the other files of its package.
+type PackageDecls struct {
+	decls         []Declaration
+	typedVars     map[string][]TypedVar
+	embeds        map[string][]DeclType
+	embeddedTypes map[string][]string
+	generics      map[string][]Declaration
+	condLockers   map[string][]CondLocker
+}
+
+func newPackageDecls() PackageDecls {
+	return PackageDecls{typedVars: map[string][]TypedVar{}, embeds: map[string][]DeclType{},
+		embeddedTypes: map[string][]string{}, generics: map[string][]Declaration{},
+		condLockers: map[string][]CondLocker{}}
+}
+
+// add adds the declarations of o to p, or only those with exported
+// names if exported is set. All types are kept, since an exported type
+// may embed an unexported one.
+func (p *PackageDecls) add(o PackageDecls, exported bool) {
+	for _, d := range o.decls {
+		if !exported || ast.IsExported(d.name) {
+			p.decls = append(p.decls, d)
+		}
+	}
+	for name, tvs := range o.typedVars {
+		if !exported || ast.IsExported(name) {
+			p.typedVars[name] = append(p.typedVars[name], tvs...)
+		}
+	}
+	for typeName, embeds := range o.embeds {
+		p.embeds[typeName] = append(p.embeds[typeName], embeds...)
+	}
+	for typeName, embedded := range o.embeddedTypes {
+		p.embeddedTypes[typeName] = append(p.embeddedTypes[typeName], embedded...)
+	}
+	for typeName, prims := range o.generics {
+		p.generics[typeName] = prims
+	}
+	for cond, lockers := range o.condLockers {
+		if !exported || ast.IsExported(cond) {
+			p.condLockers[cond] = append(p.condLockers[cond], lockers...)
+		}
+	}
+}
+
+// addTypes adds the types of o to p, without its declarations, which
+// are only visible in the files importing the package of o.
+func (p *PackageDecls) addTypes(o PackageDecls) {
+	for typeName, embeds := range o.embeds {
+		p.embeds[typeName] = append(p.embeds[typeName], embeds...)
+	}
+	for typeName, embedded := range o.embeddedTypes {
+		p.embeddedTypes[typeName] = append(p.embeddedTypes[typeName], embedded...)
+	}
+}
+
+// qualify returns the declarations of p as seen from a file importing
+// the package under name, where its types are referred to as name.T.
+// Generic types are left out.
+func (p PackageDecls) qualify(name string) PackageDecls {
+	q := newPackageDecls()
+	q.decls = p.decls
+	q.condLockers = p.condLockers
+	qualified := func(typeName string) string {
+		if strings.Contains(typeName, ".") {
+			return typeName
+		}
+		return name + "." + typeName
+	}
+	for varName, tvs := range p.typedVars {
+		for _, tv := range tvs {
+			tv.typeName = qualified(tv.typeName)
+			q.typedVars[varName] = append(q.typedVars[varName], tv)
+		}
+	}
+	for typeName, embeds := range p.embeds {
+		q.embeds[qualified(typeName)] = embeds
+	}
+	for typeName, embedded := range p.embeddedTypes {
+		for _, e := range embedded {
+			q.embeddedTypes[qualified(typeName)] = append(q.embeddedTypes[qualified(typeName)], qualified(e))
+		}
+	}
+	return q
+}
+
+// packageDecls returns the declarations of the file whose scope is the
+// whole file, such as global variables and struct fields, with the
+// types and Cond lockers declared in it.
+func (s *AnalysisState) packageDecls() PackageDecls {
+	p := newPackageDecls()
+	for typeName, embeds := range s.embeds {
+		p.embeds[typeName] = append([]DeclType{}, embeds...)
+	}
+	for typeName, embedded := range s.embeddedTypes {
+		p.embeddedTypes[typeName] = append([]string{}, embedded...)
+	}
+	for typeName, prims := range s.generics {
+		p.generics[typeName] = prims
+	}
+	for cond, lockers := range s.condLockers {
+		for _, l := range lockers {
+			if l.scope == (Scope{}) {
+				p.condLockers[cond] = append(p.condLockers[cond], l)
+			}
+		}
+	}
+	for _, vs := range s.decls {
+		for _, d := range vs {
+			if d.scope == (Scope{}) {
+				p.decls = append(p.decls, d)
+			}
+		}
+	}
+	for name, tvs := range s.typedVars {
+		for _, tv := range tvs {
+			if tv.scope == (Scope{}) {
+				p.typedVars[name] = append(p.typedVars[name], tv)
+			}
+		}
+	}
+	return p
+}
+
+// addPackageDecls adds the package-level declarations of a file of the
+// package, or of an imported package. They are not counted, since they
+// are counted in the file that declares them.
+func (s *AnalysisState) addPackageDecls(p PackageDecls) {
+	for _, d := range p.decls {
+		s.addDecl(d)
+	}
+	for name, tvs := range p.typedVars {
+		s.typedVars[name] = append(s.typedVars[name], tvs...)
+	}
+	for typeName, embeds := range p.embeds {
+		s.embeds[typeName] = append(s.embeds[typeName], embeds...)
+	}
+	for typeName, embedded := range p.embeddedTypes {
+		s.embeddedTypes[typeName] = append(s.embeddedTypes[typeName], embedded...)
+	}
+	for typeName, prims := range p.generics {
+		if _, ok := s.generics[typeName]; !ok {
+			s.generics[typeName] = prims
+		}
+	}
+	for cond, lockers := range p.condLockers {
+		s.condLockers[cond] = append(s.condLockers[cond], lockers...)
+	}
+}
+
+func (s *AnalysisState) addCondLocker(cond string, locker CondLocker) {
+	s.condLockers[cond] = append(s.condLockers[cond], locker)
+}
+
+// checkCondLockers counts the distinct lockers passed to sync.NewCond,
+// and those shared by more than one Cond.
+func (s *AnalysisState) checkCondLockers() {
+	conds := map[string]map[string]bool{}
+	var keys []string
+	for cond, lockers := range s.condLockers {
+		for _, l := range lockers {
+			if conds[l.key] == nil {
+				conds[l.key] = map[string]bool{}
+				keys = append(keys, l.key)
+			}
+			conds[l.key][cond] = true
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s.addCondLockers()
+		if len(conds[key]) > 1 {
+			fmt.Printf("Found locker %s shared by %d Conds\n", key, len(conds[key]))
+			s.addCondSharedLocker()
+		}
+	}
+}
+
+// condLockersOf returns the lockers passed to sync.NewCond for the Cond
+// named cond, or for the Conds it is an alias of, such as a parameter
+// bound to button.Clicked.
+func (s *AnalysisState) condLockersOf(cond string, depth int) []CondLocker {
+	var lockers []CondLocker
+	for _, l := range s.condLockers[cond] {
+		if l.scope.contains(s.pos) {
+			lockers = append(lockers, l)
+		}
+	}
+	if s.pkg != nil {
+		for _, l := range s.pkg.condLockers[cond] {
+			if l.scope.contains(s.pos) && !containsCondLocker(lockers, l) {
+				lockers = append(lockers, l)
+			}
+		}
+	}
+	if len(lockers) > 0 || depth > maxAliasDepth {
+		return lockers
+	}
+	pos := s.pos
+	defer func() { s.pos = pos }()
+	for _, a := range s.visibleAliases(cond) {
+		s.pos = a.pos
+		lockers = append(lockers, s.condLockersOf(splitTarget(a.target), depth+1)...)
+	}
+	return lockers
+}
+
+func containsCondLocker(lockers []CondLocker, locker CondLocker) bool {
+	for _, l := range lockers {
+		if l == locker {
+			return true
+		}
+	}
+	return false
+}
+
+// addCondLockerUse attributes a call of Lock or Unlock on the L field
+// of the Cond named cond to the locker passed to sync.NewCond.
+func (s *AnalysisState) addCondLockerUse(cond string, method string) {
+	lockers := s.condLockersOf(cond, 0)
+	if len(lockers) == 0 {
+		fmt.Printf("No locker known for Cond target %s\n", cond)
+		return
+	}
+	for _, l := range lockers[1:] {
+		if l.key != lockers[0].key {
+			fmt.Printf("Multiple lockers for Cond target %s\n", cond)
+			return
+		}
+	}
+	l := lockers[0]
+	typeof := l.typeof
+	if l.target != "" {
+		vs, ok := s.lookup(l.target)
+		if !ok || len(vs) != 1 {
+			fmt.Printf("Could not resolve locker %s of Cond target %s\n", l.target, cond)
+			return
+		}
+		typeof = vs[0].typeof
+	}
+	fmt.Printf("Found use of %s for %s %s through Cond target %s\n", method, typeof.String(), l.key, cond)
+	switch {
+	case typeof == Mutex && method == "Lock":
+		s.addMutexLock()
+	case typeof == Mutex && method == "Unlock":
+		s.addMutexUnlock()
+	case typeof == RWMutex && l.readSide && method == "Lock":
+		s.addRWMutexRLock()
+	case typeof == RWMutex && l.readSide && method == "Unlock":
+		s.addRWMutexRUnlock()
+	case typeof == RWMutex && method == "Lock":
+		s.addRWMutexLock()
+	case typeof == RWMutex && method == "Unlock":
+		s.addRWMutexUnlock()
+	case typeof == Locker && method == "Lock":
+		s.addLockerLock()
+	case typeof == Locker && method == "Unlock":
+		s.addLockerUnlock()
+	}
+}
+
+func (s *AnalysisState) addDotImport(path string) {
+	fmt.Printf("Adding dot import of %s\n", path)
+	s.dotImports = append(s.dotImports, path)
+}
+
+func (s *AnalysisState) isImportName(name string) bool {
+	_, ok := s.imports[name]
+	return ok
+}
+
+func (s *AnalysisState) isPackage(name string, path string) bool {
+	p, ok := s.imports[name]
+	return ok && p == path
+}
+
+func (s *AnalysisState) isDotImport(path string) bool {
+	for _, p := range s.dotImports {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *AnalysisState) addAtomicFuncAdd() {
+	s.counts.atomicFuncAdd++
+}
+
+func (s *AnalysisState) addAtomicFuncLoad() {
+	s.counts.atomicFuncLoad++
+}
+
+func (s *AnalysisState) addAtomicFuncStore() {
+	s.counts.atomicFuncStore++
+}
+
+func (s *AnalysisState) addAtomicFuncSwap() {
+	s.counts.atomicFuncSwap++
+}
+
+func (s *AnalysisState) addAtomicFuncCAS() {
+	s.counts.atomicFuncCAS++
+}
+
+func (s *AnalysisState) addAtomicValueLoad() {
+	s.counts.atomicValueLoad++
+}
+
+func (s *AnalysisState) addAtomicValueStore() {
+	s.counts.atomicValueStore++
+}
+
+func (s *AnalysisState) addAtomicValueSwap() {
+	s.counts.atomicValueSwap++
+}
+
+func (s *AnalysisState) addAtomicValueCAS() {
+	s.counts.atomicValueCAS++
+}
+
+func (s *AnalysisState) addAtomicTypedLoad() {
+	s.counts.atomicTypedLoad++
+}
+
+func (s *AnalysisState) addAtomicTypedStore() {
+	s.counts.atomicTypedStore++
+}
+
+func (s *AnalysisState) addAtomicTypedAdd() {
+	s.counts.atomicTypedAdd++
+}
+
+func (s *AnalysisState) addAtomicTypedSwap() {
+	s.counts.atomicTypedSwap++
+}
+
+func (s *AnalysisState) addAtomicTypedCAS() {
+	s.counts.atomicTypedCAS++
+}
+
+func (s *AnalysisState) addSyncMapLoad() {
+	s.counts.syncMapLoad++
+}
+
+func (s *AnalysisState) addSyncMapStore() {
+	s.counts.syncMapStore++
+}
+
+func (s *AnalysisState) addSyncMapLoadStore() {
+	s.counts.syncMapLoadStore++
+}
+
+func (s *AnalysisState) addSyncMapLoadDel() {
+	s.counts.syncMapLoadDel++
+}
+
+func (s *AnalysisState) addSyncMapDelete() {
+	s.counts.syncMapDelete++
+}
+
+func (s *AnalysisState) addSyncMapRange() {
+	s.counts.syncMapRange++
+}
+
+func (s *AnalysisState) addSyncMapSwap() {
+	s.counts.syncMapSwap++
+}
+
+func (s *AnalysisState) addSyncMapCAS() {
+	s.counts.syncMapCAS++
+}
+
+func (s *AnalysisState) addPoolGet() {
+	s.counts.poolGet++
+}
+
+func (s *AnalysisState) addPoolPut() {
+	s.counts.poolPut++
+}
+
+func (s *AnalysisState) addPoolNew() {
+	s.counts.poolNew++
+}
+
+func (s *AnalysisState) addErrGroupGo() {
+	s.counts.errGroupGo++
+}
+
+func (s *AnalysisState) addErrGroupTryGo() {
+	s.counts.errGroupTryGo++
+}
+
+func (s *AnalysisState) addErrGroupWait() {
+	s.counts.errGroupWait++
+}
+
+func (s *AnalysisState) addErrGroupSetLimit() {
+	s.counts.errGroupSetLimit++
+}
+
+func (s *AnalysisState) addErrGroupWithCtx() {
+	s.counts.errGroupWithCtx++
+}
+
+func (s *AnalysisState) addSemAcquire() {
+	s.counts.semAcquire++
+}
+
+func (s *AnalysisState) addSemTryAcquire() {
+	s.counts.semTryAcquire++
+}
+
+func (s *AnalysisState) addSemRelease() {
+	s.counts.semRelease++
+}
+
+func (s *AnalysisState) addSingleFlightDo() {
+	s.counts.singleFlightDo++
+}
+
+func (s *AnalysisState) addSingleFlightChan() {
+	s.counts.singleFlightChan++
+}
+
+func (s *AnalysisState) addSingleFlightFgt() {
+	s.counts.singleFlightFgt++
+}
+
+func (s *AnalysisState) addTimeAfter() {
+	s.counts.timeAfter++
+}
+
+func (s *AnalysisState) addTimeTick() {
+	s.counts.timeTick++
+}
+
+func (s *AnalysisState) addTimeNewTimer() {
+	s.counts.timeNewTimer++
+}
+
+func (s *AnalysisState) addTimeNewTicker() {
+	s.counts.timeNewTicker++
+}
+
+func (s *AnalysisState) addTimeAfterFunc() {
+	s.counts.timeAfterFunc++
+}
+
+func (s *AnalysisState) addTimerStop() {
+	s.counts.timerStop++
+}
+
+func (s *AnalysisState) addTimerReset() {
+	s.counts.timerReset++
+}
+
+func (s *AnalysisState) addTickerStop() {
+	s.counts.tickerStop++
+}
+
+func (s *AnalysisState) addTickerReset() {
+	s.counts.tickerReset++
+}
+
+func (s *AnalysisState) addTickerNotStopped() {
+	s.counts.tickerNotStopped++
+}
+
+func (s *AnalysisState) addTimeAfterInLoop() {
+	s.counts.timeAfterInLoop++
+}
+
+func (s *AnalysisState) addTicker(target string, pos token.Pos) {
+	fmt.Printf("Adding ticker %s\n", target)
+	s.tickers = append(s.tickers, TickerTarget{target: target, pos: pos})
+}
+
+// hasDeclType checks if target matches a single declaration of one of
+// the given types.
+func (s *AnalysisState) hasDeclType(target string, types ...DeclType) bool {
+	vs, ok := s.lookup(target)
+	if ok && len(vs) == 1 {
+		for _, typeof := range types {
+			if vs[0].typeof == typeof {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *AnalysisState) isTimerTarget(target string) bool {
+	return s.hasDeclType(target, Timer, Ticker)
+}
+
+// checkTickers warns about tickers that are never stopped, which leak
+// until the program exits. Each ticker is resolved to its declaration
+// where it is assigned, so that stopping t in one function does not
+// count for another t.
+func (s *AnalysisState) checkTickers() {
+	pos := s.pos
+	for _, t := range s.tickers {
+		s.pos = t.pos
+		vs, ok := s.lookupDecls(t.target)
+		if !ok || len(vs) != 1 || !s.stoppedTickers[vs[0]] {
+			fmt.Printf("Warning: ticker %s is never stopped\n", t.target)
+			s.addTickerNotStopped()
+		}
+	}
+	s.pos = pos
+}
+
+func (s *AnalysisState) addRtGosched() {
+	s.counts.rtGosched++
+}
+
+func (s *AnalysisState) addRtGOMAXPROCS() {
+	s.counts.rtGOMAXPROCS++
+}
+
+func (s *AnalysisState) addRtLockOSThread() {
+	s.counts.rtLockOSThread++
+}
+
+func (s *AnalysisState) addRtUnlockOSThread() {
+	s.counts.rtUnlockOSThread++
+}
+
+func (s *AnalysisState) addRtNumGoroutine() {
+	s.counts.rtNumGoroutine++
+}
+
+func (s *AnalysisState) addRtGoexit() {
+	s.counts.rtGoexit++
+}
+
+func (s *AnalysisState) addDbgSetMaxThreads() {
+	s.counts.dbgSetMaxThreads++
+}
+
+func (s *AnalysisState) addCtxWithCancel() {
+	s.counts.ctxWithCancel++
+}
+
+func (s *AnalysisState) addCtxWithTimeout() {
+	s.counts.ctxWithTimeout++
+}
+
+func (s *AnalysisState) addCtxWithDeadline() {
+	s.counts.ctxWithDeadline++
+}
+
+func (s *AnalysisState) addCtxWithValue() {
+	s.counts.ctxWithValue++
+}
+
+func (s *AnalysisState) addCtxWithCause() {
+	s.counts.ctxWithCause++
+}
+
+func (s *AnalysisState) addCtxDone() {
+	s.counts.ctxDone++
+}
+
+func (s *AnalysisState) addCtxErr() {
+	s.counts.ctxErr++
+}
+
+func (s *AnalysisState) addSelectStmt() {
+	s.counts.selectStmts++
+}
+
+func (s *AnalysisState) addSelectCase() {
+	s.counts.selectCases++
+}
+
+func (s *AnalysisState) addSelectSendCase() {
+	s.counts.selectSendCases++
+}
+
+func (s *AnalysisState) addSelectRecvCase() {
+	s.counts.selectRecvCases++
+}
+
+func (s *AnalysisState) addSelectDefault() {
+	s.counts.selectDefault++
+}
+
+func (s *AnalysisState) addSelectTimerCase() {
+	s.counts.selectTimerCases++
+}
+
+func (s *AnalysisState) addSelectEmpty() {
+	s.counts.selectEmpty++
+}
+
+func (s *AnalysisState) addSelectSingleCase() {
+	s.counts.selectSingleCase++
+}
+
+func (s *AnalysisState) addSelectInLoop() {
+	s.counts.selectInLoop++
+}
+
+func (s *AnalysisState) addGoStmt() {
+	s.counts.goStmts++
+}
+
+func (s *AnalysisState) addGoNamedFunc() {
+	s.counts.goNamedFunc++
+}
+
+func (s *AnalysisState) addGoMethod() {
+	s.counts.goMethod++
+}
+
+func (s *AnalysisState) addGoClosure() {
+	s.counts.goClosure++
+}
+
+func (s *AnalysisState) addGoOther() {
+	s.counts.goOther++
+}
+
+func (s *AnalysisState) addGoInLoop() {
+	s.counts.goInLoop++
+}
+
+func (s *AnalysisState) addGoLoopVarArg() {
+	s.counts.goLoopVarArg++
+}
+
+func (s *AnalysisState) addGoLoopVarCapture() {
+	s.counts.goLoopVarCapture++
+}
+
+func (s *AnalysisState) addOnceFunc() {
+	s.counts.onceFunc++
+}
+
+func (s *AnalysisState) addOnceValue() {
+	s.counts.onceValue++
+}
+
+func (s *AnalysisState) addOnceValues() {
+	s.counts.onceValues++
+}
+
+func (s *AnalysisState) addWaitGroupGo() {
+	s.counts.waitGroupGo++
+}
+
+func (s *AnalysisState) addLegacyOnceValue() {
+	s.counts.legacyOnceValue++
+}
+
+func (s *AnalysisState) addLegacyWgGo() {
+	s.counts.legacyWgGo++
+}
+
+func (s *AnalysisState) addTestParallel() {
+	s.counts.testParallel++
+}
+
+func (s *AnalysisState) addBenchRunParallel() {
+	s.counts.benchRunParallel++
+}
+
+func (s *AnalysisState) addBenchSetParallel() {
+	s.counts.benchSetParallel++
+}
+
+func (s *AnalysisState) addPbNext() {
+	s.counts.pbNext++
+}
+
+func (s *AnalysisState) addGoInTest() {
+	s.counts.goInTest++
+}
+
+func (s *AnalysisState) addGoInBenchmark() {
+	s.counts.goInBenchmark++
+}
+
+func (s *AnalysisState) addSignalNotify() {
+	s.counts.signalNotify++
+}
+
+func (s *AnalysisState) addSignalStop() {
+	s.counts.signalStop++
+}
+
+func (s *AnalysisState) addSignalIgnore() {
+	s.counts.signalIgnore++
+}
+
+func (s *AnalysisState) addSignalNotifyCtx() {
+	s.counts.signalNotifyCtx++
+}
+
+func (s *AnalysisState) addSignalUnbuffered() {
+	s.counts.signalUnbuffered++
+}
+
+func (s *AnalysisState) addGenericTypes() {
+	s.counts.genericTypes++
+}
+
+func (s *AnalysisState) addGenericInsts() {
+	s.counts.genericInsts++
+}
+
+func (s *AnalysisState) addCondLockers() {
+	s.counts.condLockers++
+}
+
+func (s *AnalysisState) addCondSharedLocker() {
+	s.counts.condSharedLocker++
+}
+
+func (s *AnalysisState) addPrimArrayDecls() {
+	s.counts.primArrayDecls++
+}
+
+func (s *AnalysisState) addPrimSliceDecls() {
+	s.counts.primSliceDecls++
+}
+
+func (s *AnalysisState) addPrimMapDecls() {
+	s.counts.primMapDecls++
+}
+
+func (s *AnalysisState) addIndexedCalls() {
+	s.counts.indexedCalls++
+}
+
+func (s *AnalysisState) addCollectionDecl(kind CollectionKind) {
+	switch kind {
+	case ArrayCollection:
+		s.addPrimArrayDecls()
+	case SliceCollection:
+		s.addPrimSliceDecls()
+	case MapCollection:
+		s.addPrimMapDecls()
+	}
+}
+
+func (s *AnalysisState) addTypedCalls() {
+	s.counts.typedCalls++
+}
+
+func (s *AnalysisState) addTypeFallbacks() {
+	s.counts.typeFallbacks++
+}
+
+func (s *AnalysisState) addPrimAliases() {
+	s.counts.primAliases++
+}
+
+func (s *AnalysisState) addAliasedUses() {
+	s.counts.aliasedUses++
+}
+
+func (s *AnalysisState) addUnknownDone() {
+	s.counts.unknownDone++
+}
+
+func (s *AnalysisState) addUnknownAdd() {
+	s.counts.unknownAdd++
+}
+
+func (s *AnalysisState) addUnknownWait() {
+	s.counts.unknownWait++
+}
+
+func (s *AnalysisState) addUnknownLock() {
+	s.counts.unknownLock++
+}
+
+func (s *AnalysisState) addUnknownUnlock() {
+	s.counts.unknownUnlock++
+}
+
+func (s *AnalysisState) addUnknownRLock() {
+	s.counts.unknownRLock++
+}
+
+func (s *AnalysisState) addUnknownRUnlock() {
+	s.counts.unknownRUnlock++
+}
+
+func (s *AnalysisState) addUnknownRLocker() {
+	s.counts.unknownRLocker++
+}
+
+func (s *AnalysisState) addUnknownTryLock() {
+	s.counts.unknownTryLock++
+}
+
+func (s *AnalysisState) addUnknownTryRLock() {
+	s.counts.unknownTryRLock++
+}
+
+func (s *AnalysisState) addUnknownSignal() {
+	s.counts.unknownSignal++
+}
+
+func (s *AnalysisState) addUnknownBroadcast() {
+	s.counts.unknownBroadcast++
+}
+
+func (s *AnalysisState) addUnknownDo() {
+	s.counts.unknownDo++
+}
+
+func (s *AnalysisState) addUnknownLoad() {
+	s.counts.unknownLoad++
+}
+
+func (s *AnalysisState) addUnknownStore() {
+	s.counts.unknownStore++
+}
+
+func (s *AnalysisState) addUnknownSwap() {
+	s.counts.unknownSwap++
+}
+
+func (s *AnalysisState) addUnknownCAS() {
+	s.counts.unknownCAS++
+}
+
+func (s *AnalysisState) addUnknownLoadStore() {
+	s.counts.unknownLoadStore++
+}
+
+func (s *AnalysisState) addUnknownLoadDel() {
+	s.counts.unknownLoadDel++
+}
+
+func (s *AnalysisState) addUnknownDelete() {
+	s.counts.unknownDelete++
+}
+
+func (s *AnalysisState) addUnknownRange() {
+	s.counts.unknownRange++
+}
+
+func (s *AnalysisState) addUnknownGet() {
+	s.counts.unknownGet++
+}
+
+func (s *AnalysisState) addUnknownPut() {
+	s.counts.unknownPut++
+}
+
+func (s *AnalysisState) addUnknownGo() {
+	s.counts.unknownGo++
+}
+
+func (s *AnalysisState) addUnknownTryGo() {
+	s.counts.unknownTryGo++
+}
+
+func (s *AnalysisState) addUnknownSetLimit() {
+	s.counts.unknownSetLimit++
+}
+
+func (s *AnalysisState) addUnknownAcquire() {
+	s.counts.unknownAcquire++
+}
+
+func (s *AnalysisState) addUnknownTryAcq() {
+	s.counts.unknownTryAcq++
+}
+
+func (s *AnalysisState) addUnknownRelease() {
+	s.counts.unknownRelease++
+}
+
+func (s *AnalysisState) addUnknownDoChan() {
+	s.counts.unknownDoChan++
+}
+
+func (s *AnalysisState) addUnknownForget() {
+	s.counts.unknownForget++
+}
+
+func (s *AnalysisState) addUnknownStop() {
+	s.counts.unknownStop++
+}
+
+func (s *AnalysisState) addUnknownReset() {
+	s.counts.unknownReset++
+}
+
+func (s *AnalysisState) addUnknownSend() {
+	s.counts.unknownSend++
+}
+
+func (s *AnalysisState) addUnknownRecv() {
+	s.counts.unknownRecv++
+}
+
+func (s *AnalysisState) addUnknownClose() {
+	s.counts.unknownClose++
+}
+
+// adoptableOnceFunc returns the number of legacy sync.Once idioms that
+// could use sync.OnceFunc, OnceValue or OnceValues, which need Go 1.21.
+func (s *AnalysisState) adoptableOnceFunc() int {
+	if goVersionAtLeast(s.goVersion, 21) {
+		return s.counts.legacyOnceValue
+	}
+	return 0
+}
+
+// adoptableWaitGroupGo returns the number of legacy WaitGroup idioms
+// that could use WaitGroup.Go, which needs Go 1.25.
+func (s *AnalysisState) adoptableWaitGroupGo() int {
+	if goVersionAtLeast(s.goVersion, 25) {
+		return s.counts.legacyWgGo
+	}
+	return 0
+}
+
+// goVersionAtLeast checks if the go directive version, such as 1.21 or
+// 1.22.3, is at least Go 1.minor.
+func goVersionAtLeast(version string, minor int) bool {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 || parts[0] != "1" {
+		return false
+	}
+	digits := parts[1]
+	for i, c := range digits {
+		if c < '0' || c > '9' {
+			digits = digits[:i]
+			break
+		}
+	}
+	m, err := strconv.Atoi(digits)
+	return err == nil && m >= minor
+}
+
+// Module describes the module a file belongs to, as given by the
+// nearest go.mod file.
+type Module struct {
+	// The directory of the go.mod file, the module path and the go
+	// directive, all "" if there is no go.mod file
+	root      string
+	path      string
+	goVersion string
+}
+
+// findModule reads the go.mod file in dir or its nearest parent.
+func findModule(dir string) Module {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Module{}
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			m := Module{root: dir}
+			for _, line := range strings.Split(string(data), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 2 && fields[0] == "module" {
+					m.path = strings.Trim(fields[1], `"`)
+				} else if len(fields) == 2 && fields[0] == "go" {
+					m.goVersion = fields[1]
+				}
+			}
+			return m
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Module{}
+		}
+		dir = parent
+	}
+}
+
+// importDir returns the directory of the package with the given import
+// path if it belongs to the module, or "" otherwise.
+func (m Module) importDir(path string) string {
+	if m.path == "" {
+		return ""
+	} else if path == m.path {
+		return m.root
+	} else if strings.HasPrefix(path, m.path+"/") {
+		return filepath.Join(m.root, filepath.FromSlash(strings.TrimPrefix(path, m.path+"/")))
+	}
+	return ""
+}
+
+// parsePackageDir parses the files of the package in dir, leaving out
+// test files and files of other packages.
+func parsePackageDir(fset *token.FileSet, dir string) []*ast.File {
+	var files []*ast.File
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err == nil && (len(files) == 0 || file.Name.Name == files[0].Name.Name) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// loadModulePackage returns the exported package-level declarations of
+// the package of module m in dir, with the types declared in it and in
+// the packages of the module it imports, so that a type embedding a type
+// of another package resolves transitively.
+func (s *AnalysisState) loadModulePackage(m Module, dir string, options Options) PackageDecls {
+	p, ok := s.modulePackages[dir]
+	if ok {
+		return p
+	}
+	fmt.Printf("Loading module package in %s\n", dir)
+	p = newPackageDecls()
+	s.modulePackages[dir] = p
+	fset := token.NewFileSet()
+	files := parsePackageDir(fset, dir)
+	options.typeCheck = false
+	for _, file := range files {
+		fileState := newAnalysisState(fset.Position(file.Pos()).Filename, Module{}, options)
+		declVisitor := &Visitor{fset: fset, mode: true, state: fileState}
+		ast.Walk(declVisitor, file)
+		p.add(fileState.packageDecls(), true)
+		for name, path := range fileState.imports {
+			importDir := m.importDir(path)
+			if importDir != "" && importDir != dir {
+				p.addTypes(s.loadModulePackage(m, importDir, options).qualify(name))
+			}
+		}
+		for _, path := range fileState.dotImports {
+			importDir := m.importDir(path)
+			if importDir != "" && importDir != dir {
+				p.addTypes(s.loadModulePackage(m, importDir, options))
+			}
+		}
+	}
+	s.modulePackages[dir] = p
+	return p
+}
+
+// addModuleImports adds the declarations of the packages of module m
+// imported by the file.
+func (s *AnalysisState) addModuleImports(m Module, dir string, options Options) {
+	for name, path := range s.imports {
+		importDir := m.importDir(path)
+		if importDir != "" && importDir != dir {
+			fmt.Printf("Adding declarations of module package %s as %s\n", path, name)
+			s.addPackageDecls(s.loadModulePackage(m, importDir, options).qualify(name))
+		}
+	}
+	for _, path := range s.dotImports {
+		importDir := m.importDir(path)
+		if importDir != "" && importDir != dir {
+			fmt.Printf("Adding declarations of module package %s\n", path)
+			s.addPackageDecls(s.loadModulePackage(m, importDir, options))
+		}
+	}
+}
+
+// addModuleTypes adds the types of the packages of module m imported by
+// file, so that the package-level variables of the package whose type
+// embeds one of them are resolved once for all the files.
+func (s *AnalysisState) addModuleTypes(file *AnalysisState, m Module, dir string, options Options) {
+	p := newPackageDecls()
+	for name, path := range file.imports {
+		importDir := m.importDir(path)
+		if importDir != "" && importDir != dir && s.imports[name] != path {
+			s.imports[name] = path
+			p.addTypes(s.loadModulePackage(m, importDir, options).qualify(name))
+		}
+	}
+	s.addPackageDecls(p)
+}
+
+// stripIndexes removes the index expressions from target, so that
+// locks[i] becomes locks and shards[h.Sum()%n].mu becomes shards.mu.
+func stripIndexes(target string) string {
+	var b strings.Builder
+	depth := 0
+	for _, c := range target {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func splitTarget(target string) string {
+	parts := strings.Split(stripIndexes(target), ".")
+	return parts[len(parts)-1]
+}
+
+func targetPieces(target string) int {
+	parts := strings.Split(stripIndexes(target), ".")
+	return len(parts)
+}
+
+func stateHeaders() []string {
+	res := []string{"fileName", "waitGroupDecls", "condDecls", "onceDecls",
+		"mutexDecls", "rwMutexDecls", "lockerDecls", "contextDecls",
+		"atomicValueDecls", "atomicTypedDecls", "syncMapDecls", "poolDecls",
+		"errGroupDecls", "semaphoreDecls", "singleFlightDecl", "timerDecls",
+		"tickerDecls",
+		"chanDecls", "bidiChanDecls", "sendChanDecls", "recvChanDecls",
+		"chanMake", "unbufferedMake", "literalBufMake", "computedBufMake",
+		"waitGroupDone",
+		"waitGroupAdd", "waitGroupWait", "mutexLock", "mutexUnlock",
+		"rwMutexLock", "rwMutexUnlock", "rwMutexRLock", "rwMutexRUnlock",
+		"rwMutexRLocker", "rwMutexTryLock", "rwMutexTryRLock", "mutexTryLock",
+		"lockerLock", "lockerUnlock",
+		"condLock", "condUnlock",
+		"condWait", "condSignal", "condBroadcast", "condNew",
+		"onceDo", "onceFunc", "onceValue", "onceValues", "waitGroupGo",
+		"legacyOnceValue", "legacyWgGo", "goVersion", "adoptOnceFunc",
+		"adoptWgGo", "chanSend", "chanRecv", "chanRecvOk", "chanRange",
+		"chanClose", "goStmts", "goNamedFunc", "goMethod", "goClosure",
+		"goOther", "goInLoop", "goLoopVarArg", "goLoopVarCapture",
+		"atomicFuncAdd", "atomicFuncLoad", "atomicFuncStore", "atomicFuncSwap",
+		"atomicFuncCAS", "atomicValueLoad", "atomicValueStore", "atomicValueSwap",
+		"atomicValueCAS", "atomicTypedLoad", "atomicTypedStore", "atomicTypedAdd",
+		"atomicTypedSwap", "atomicTypedCAS",
+		"syncMapLoad", "syncMapStore", "syncMapLoadStore", "syncMapLoadDel",
+		"syncMapDelete", "syncMapRange", "syncMapSwap", "syncMapCAS",
+		"poolGet", "poolPut", "poolNew",
+		"errGroupGo", "errGroupTryGo", "errGroupWait", "errGroupSetLimit",
+		"errGroupWithCtx", "semAcquire", "semTryAcquire", "semRelease",
+		"singleFlightDo", "singleFlightChan", "singleFlightFgt",
+		"timeAfter", "timeTick", "timeNewTimer", "timeNewTicker", "timeAfterFunc",
+		"timerStop", "timerReset", "tickerStop", "tickerReset", "tickerNotStopped",
+		"timeAfterInLoop", "rtGosched", "rtGOMAXPROCS", "rtLockOSThread",
+		"rtUnlockOSThread", "rtNumGoroutine", "rtGoexit", "dbgSetMaxThreads",
+		"testFile", "testParallel", "benchRunParallel", "benchSetParallel", "pbNext",
+		"goInTest", "goInBenchmark", "signalNotify", "signalStop",
+		"signalIgnore", "signalNotifyCtx", "signalUnbuffered", "genericTypes",
+		"genericInsts", "condLockers", "condSharedLocker",
+		"primArrayDecls", "primSliceDecls", "primMapDecls", "indexedCalls",
+		"typedCalls", "typeFallbacks", "primAliases", "aliasedUses",
+		"ctxWithCancel", "ctxWithTimeout", "ctxWithDeadline", "ctxWithValue",
+		"ctxWithCause", "ctxDone", "ctxErr",
+		"selectStmts", "selectCases", "selectSendCases", "selectRecvCases",
+		"selectDefault", "selectTimerCases", "selectEmpty", "selectSingleCase",
+		"selectInLoop", "unknownDone", "unknownAdd", "unknownWait",
+		"unknownLock", "unknownUnlock", "unknownRLock", "unknownRUnlock",
+		"unknownRLocker", "unknownTryLock", "unknownTryRLock",
+		"unknownSignal", "unknownBroadcast",
+		"unknownDo", "unknownLoad", "unknownStore", "unknownSwap", "unknownCAS",
+		"unknownLoadStore", "unknownLoadDel", "unknownDelete", "unknownRange",
+		"unknownGet", "unknownPut", "unknownGo", "unknownTryGo", "unknownSetLimit",
+		"unknownAcquire", "unknownTryAcq", "unknownRelease", "unknownDoChan",
+		"unknownForget", "unknownStop", "unknownReset", "unknownSend", "unknownRecv", "unknownClose",
+	}
+	return res
+}
+
+func (s *AnalysisState) stateToSlice(fileName string) []string {
+	res := []string{fileName, strconv.Itoa(s.counts.waitGroupDecls),
+		strconv.Itoa(s.counts.condDecls), strconv.Itoa(s.counts.onceDecls),
+		strconv.Itoa(s.counts.mutexDecls), strconv.Itoa(s.counts.rwMutexDecls),
+		strconv.Itoa(s.counts.lockerDecls), strconv.Itoa(s.counts.contextDecls),
+		strconv.Itoa(s.counts.atomicValueDecls), strconv.Itoa(s.counts.atomicTypedDecls),
+		strconv.Itoa(s.counts.syncMapDecls), strconv.Itoa(s.counts.poolDecls),
+		strconv.Itoa(s.counts.errGroupDecls), strconv.Itoa(s.counts.semaphoreDecls),
+		strconv.Itoa(s.counts.singleFlightDecl), strconv.Itoa(s.counts.timerDecls),
+		strconv.Itoa(s.counts.tickerDecls),
+		strconv.Itoa(s.counts.chanDecls), strconv.Itoa(s.counts.bidiChanDecls),
+		strconv.Itoa(s.counts.sendChanDecls), strconv.Itoa(s.counts.recvChanDecls),
+		strconv.Itoa(s.counts.chanMake), strconv.Itoa(s.counts.unbufferedMake),
+		strconv.Itoa(s.counts.literalBufMake), strconv.Itoa(s.counts.computedBufMake),
+		strconv.Itoa(s.counts.waitGroupDone),
+		strconv.Itoa(s.counts.waitGroupAdd), strconv.Itoa(s.counts.waitGroupWait),
+		strconv.Itoa(s.counts.mutexLock), strconv.Itoa(s.counts.mutexUnlock),
+		strconv.Itoa(s.counts.rwMutexLock), strconv.Itoa(s.counts.rwMutexUnlock),
+		strconv.Itoa(s.counts.rwMutexRLock), strconv.Itoa(s.counts.rwMutexRUnlock),
+		strconv.Itoa(s.counts.rwMutexRLocker), strconv.Itoa(s.counts.rwMutexTryLock),
+		strconv.Itoa(s.counts.rwMutexTryRLock), strconv.Itoa(s.counts.mutexTryLock),
+		strconv.Itoa(s.counts.lockerLock), strconv.Itoa(s.counts.lockerUnlock),
+		strconv.Itoa(s.counts.condLock), strconv.Itoa(s.counts.condUnlock),
+		strconv.Itoa(s.counts.condWait), strconv.Itoa(s.counts.condSignal),
+		strconv.Itoa(s.counts.condBroadcast), strconv.Itoa(s.counts.condNew),
+		strconv.Itoa(s.counts.onceDo), strconv.Itoa(s.counts.onceFunc),
+		strconv.Itoa(s.counts.onceValue), strconv.Itoa(s.counts.onceValues),
+		strconv.Itoa(s.counts.waitGroupGo), strconv.Itoa(s.counts.legacyOnceValue),
+		strconv.Itoa(s.counts.legacyWgGo), s.goVersion,
+		strconv.Itoa(s.adoptableOnceFunc()), strconv.Itoa(s.adoptableWaitGroupGo()),
+		strconv.Itoa(s.counts.chanSend),
+		strconv.Itoa(s.counts.chanRecv), strconv.Itoa(s.counts.chanRecvOk),
+		strconv.Itoa(s.counts.chanRange), strconv.Itoa(s.counts.chanClose),
+		strconv.Itoa(s.counts.goStmts), strconv.Itoa(s.counts.goNamedFunc),
+		strconv.Itoa(s.counts.goMethod), strconv.Itoa(s.counts.goClosure),
+		strconv.Itoa(s.counts.goOther), strconv.Itoa(s.counts.goInLoop),
+		strconv.Itoa(s.counts.goLoopVarArg), strconv.Itoa(s.counts.goLoopVarCapture),
+		strconv.Itoa(s.counts.atomicFuncAdd), strconv.Itoa(s.counts.atomicFuncLoad),
+		strconv.Itoa(s.counts.atomicFuncStore), strconv.Itoa(s.counts.atomicFuncSwap),
+		strconv.Itoa(s.counts.atomicFuncCAS), strconv.Itoa(s.counts.atomicValueLoad),
+		strconv.Itoa(s.counts.atomicValueStore), strconv.Itoa(s.counts.atomicValueSwap),
+		strconv.Itoa(s.counts.atomicValueCAS), strconv.Itoa(s.counts.atomicTypedLoad),
+		strconv.Itoa(s.counts.atomicTypedStore), strconv.Itoa(s.counts.atomicTypedAdd),
+		strconv.Itoa(s.counts.atomicTypedSwap), strconv.Itoa(s.counts.atomicTypedCAS),
+		strconv.Itoa(s.counts.syncMapLoad), strconv.Itoa(s.counts.syncMapStore),
+		strconv.Itoa(s.counts.syncMapLoadStore), strconv.Itoa(s.counts.syncMapLoadDel),
+		strconv.Itoa(s.counts.syncMapDelete), strconv.Itoa(s.counts.syncMapRange),
+		strconv.Itoa(s.counts.syncMapSwap), strconv.Itoa(s.counts.syncMapCAS),
+		strconv.Itoa(s.counts.poolGet), strconv.Itoa(s.counts.poolPut),
+		strconv.Itoa(s.counts.poolNew),
+		strconv.Itoa(s.counts.errGroupGo), strconv.Itoa(s.counts.errGroupTryGo),
+		strconv.Itoa(s.counts.errGroupWait), strconv.Itoa(s.counts.errGroupSetLimit),
+		strconv.Itoa(s.counts.errGroupWithCtx), strconv.Itoa(s.counts.semAcquire),
+		strconv.Itoa(s.counts.semTryAcquire), strconv.Itoa(s.counts.semRelease),
+		strconv.Itoa(s.counts.singleFlightDo), strconv.Itoa(s.counts.singleFlightChan),
+		strconv.Itoa(s.counts.singleFlightFgt),
+		strconv.Itoa(s.counts.timeAfter), strconv.Itoa(s.counts.timeTick),
+		strconv.Itoa(s.counts.timeNewTimer), strconv.Itoa(s.counts.timeNewTicker),
+		strconv.Itoa(s.counts.timeAfterFunc), strconv.Itoa(s.counts.timerStop),
+		strconv.Itoa(s.counts.timerReset), strconv.Itoa(s.counts.tickerStop),
+		strconv.Itoa(s.counts.tickerReset), strconv.Itoa(s.counts.tickerNotStopped),
+		strconv.Itoa(s.counts.timeAfterInLoop), strconv.Itoa(s.counts.rtGosched),
+		strconv.Itoa(s.counts.rtGOMAXPROCS), strconv.Itoa(s.counts.rtLockOSThread),
+		strconv.Itoa(s.counts.rtUnlockOSThread), strconv.Itoa(s.counts.rtNumGoroutine),
+		strconv.Itoa(s.counts.rtGoexit), strconv.Itoa(s.counts.dbgSetMaxThreads),
+		strconv.FormatBool(s.testFile), strconv.Itoa(s.counts.testParallel),
+		strconv.Itoa(s.counts.benchRunParallel), strconv.Itoa(s.counts.benchSetParallel),
+		strconv.Itoa(s.counts.pbNext),
+		strconv.Itoa(s.counts.goInTest), strconv.Itoa(s.counts.goInBenchmark),
+		strconv.Itoa(s.counts.signalNotify), strconv.Itoa(s.counts.signalStop),
+		strconv.Itoa(s.counts.signalIgnore), strconv.Itoa(s.counts.signalNotifyCtx),
+		strconv.Itoa(s.counts.signalUnbuffered), strconv.Itoa(s.counts.genericTypes),
+		strconv.Itoa(s.counts.genericInsts), strconv.Itoa(s.counts.condLockers),
+		strconv.Itoa(s.counts.condSharedLocker), strconv.Itoa(s.counts.primArrayDecls),
+		strconv.Itoa(s.counts.primSliceDecls), strconv.Itoa(s.counts.primMapDecls),
+		strconv.Itoa(s.counts.indexedCalls), strconv.Itoa(s.counts.typedCalls),
+		strconv.Itoa(s.counts.typeFallbacks), strconv.Itoa(s.counts.primAliases),
+		strconv.Itoa(s.counts.aliasedUses),
+		strconv.Itoa(s.counts.ctxWithCancel), strconv.Itoa(s.counts.ctxWithTimeout),
+		strconv.Itoa(s.counts.ctxWithDeadline), strconv.Itoa(s.counts.ctxWithValue),
+		strconv.Itoa(s.counts.ctxWithCause), strconv.Itoa(s.counts.ctxDone),
+		strconv.Itoa(s.counts.ctxErr),
+		strconv.Itoa(s.counts.selectStmts), strconv.Itoa(s.counts.selectCases),
+		strconv.Itoa(s.counts.selectSendCases), strconv.Itoa(s.counts.selectRecvCases),
+		strconv.Itoa(s.counts.selectDefault), strconv.Itoa(s.counts.selectTimerCases),
+		strconv.Itoa(s.counts.selectEmpty), strconv.Itoa(s.counts.selectSingleCase),
+		strconv.Itoa(s.counts.selectInLoop), strconv.Itoa(s.counts.unknownDone),
+		strconv.Itoa(s.counts.unknownAdd), strconv.Itoa(s.counts.unknownWait),
+		strconv.Itoa(s.counts.unknownLock), strconv.Itoa(s.counts.unknownUnlock),
+		strconv.Itoa(s.counts.unknownRLock), strconv.Itoa(s.counts.unknownRUnlock),
+		strconv.Itoa(s.counts.unknownRLocker), strconv.Itoa(s.counts.unknownTryLock),
+		strconv.Itoa(s.counts.unknownTryRLock),
+		strconv.Itoa(s.counts.unknownSignal), strconv.Itoa(s.counts.unknownBroadcast),
+		strconv.Itoa(s.counts.unknownDo), strconv.Itoa(s.counts.unknownLoad),
+		strconv.Itoa(s.counts.unknownStore), strconv.Itoa(s.counts.unknownSwap),
+		strconv.Itoa(s.counts.unknownCAS), strconv.Itoa(s.counts.unknownLoadStore),
+		strconv.Itoa(s.counts.unknownLoadDel), strconv.Itoa(s.counts.unknownDelete),
+		strconv.Itoa(s.counts.unknownRange), strconv.Itoa(s.counts.unknownGet),
+		strconv.Itoa(s.counts.unknownPut), strconv.Itoa(s.counts.unknownGo),
+		strconv.Itoa(s.counts.unknownTryGo), strconv.Itoa(s.counts.unknownSetLimit),
+		strconv.Itoa(s.counts.unknownAcquire), strconv.Itoa(s.counts.unknownTryAcq),
+		strconv.Itoa(s.counts.unknownRelease), strconv.Itoa(s.counts.unknownDoChan),
+		strconv.Itoa(s.counts.unknownForget), strconv.Itoa(s.counts.unknownStop),
+		strconv.Itoa(s.counts.unknownReset), strconv.Itoa(s.counts.unknownSend),
+		strconv.Itoa(s.counts.unknownRecv), strconv.Itoa(s.counts.unknownClose),
+	}
+	return res
+}
+
+func (s *AnalysisState) addDone(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addDoneFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Done\n", target)
+			s.addUnknownDone()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Done\n", target)
+		s.addUnknownDone()
+	}
+}
+
+// addDoneFor classifies a call of Done on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addDoneFor(target string, d Declaration) {
+	if d.typeof == WaitGroup {
+		fmt.Printf("Found use of Done for WaitGroup target %s\n", d.name)
+		s.addWaitGroupDone()
+	} else if d.typeof == Context {
+		fmt.Printf("Found use of Done for Context target %s\n", d.name)
+		s.addCtxDone()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Done\n", target)
+		s.addUnknownDone()
+	}
+}
+
+func (s *AnalysisState) addAdd(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addAddFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Add\n", target)
+			s.addUnknownAdd()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Add\n", target)
+		s.addUnknownAdd()
+	}
+}
+
+// addAddFor classifies a call of Add on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addAddFor(target string, d Declaration) {
+	if d.typeof == WaitGroup {
+		fmt.Printf("Found use of Add for WaitGroup target %s\n", d.name)
+		s.addWaitGroupAdd()
+	} else if d.typeof == AtomicTyped {
+		fmt.Printf("Found use of Add for AtomicTyped target %s\n", d.name)
+		s.addAtomicTypedAdd()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Add\n", target)
+		s.addUnknownAdd()
+	}
+}
+
+func (s *AnalysisState) addWait(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addWaitFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Wait\n", target)
+			s.addUnknownWait()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Wait\n", target)
+		s.addUnknownWait()
+	}
+}
+
+// addWaitFor classifies a call of Wait on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addWaitFor(target string, d Declaration) {
+	if d.typeof == WaitGroup {
+		fmt.Printf("Found use of Wait for WaitGroup target %s\n", d.name)
+		s.addWaitGroupWait()
+	} else if d.typeof == Cond {
+		fmt.Printf("Found use of Wait for Cond target %s\n", d.name)
+		s.addCondWait()
+	} else if d.typeof == ErrGroup {
+		fmt.Printf("Found use of Wait for ErrGroup target %s\n", d.name)
+		s.addErrGroupWait()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Wait\n", target)
+		s.addUnknownWait()
+	}
+}
+
+func (s *AnalysisState) addLock(target string) {
+	ok := len(s.declsOf(target)) > 0
+	if !ok && targetPieces(target) > 1 && splitTarget(target) == "L" {
+		target = target[:len(target)-2]
+	}
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addLockFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Lock\n", target)
+			s.addUnknownLock()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Lock\n", target)
+		s.addUnknownLock()
+	}
+}
+
+// addLockFor classifies a call of Lock on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addLockFor(target string, d Declaration) {
+	if d.typeof == Cond {
+		fmt.Printf("Found use of Lock for Cond target %s\n", d.name)
+		s.addCondLock()
+		s.addCondLockerUse(d.name, "Lock")
+	} else if d.typeof == Mutex {
+		fmt.Printf("Found use of Lock for Mutex target %s\n", d.name)
+		s.addMutexLock()
+	} else if d.typeof == RWMutex && d.readSide {
+		fmt.Printf("Found use of Lock for the read side of RWMutex target %s\n", d.name)
+		s.addRWMutexRLock()
+	} else if d.typeof == RWMutex {
+		fmt.Printf("Found use of Lock for RWMutex target %s\n", d.name)
+		s.addRWMutexLock()
+	} else if d.typeof == Locker {
+		fmt.Printf("Found use of Lock for Locker target %s\n", d.name)
+		s.addLockerLock()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Lock\n", target)
+		s.addUnknownLock()
+	}
+}
+
+func (s *AnalysisState) addUnlock(target string) {
+	ok := len(s.declsOf(target)) > 0
+	if !ok && targetPieces(target) > 1 && splitTarget(target) == "L" {
+		target = target[:len(target)-2]
+	}
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addUnlockFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Unlock\n", target)
+			s.addUnknownUnlock()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Unlock\n", target)
+		s.addUnknownUnlock()
+	}
+}
+
+// addUnlockFor classifies a call of Unlock on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addUnlockFor(target string, d Declaration) {
+	if d.typeof == Cond {
+		fmt.Printf("Found use of Unlock for Cond target %s\n", d.name)
+		s.addCondUnlock()
+		s.addCondLockerUse(d.name, "Unlock")
+	} else if d.typeof == Mutex {
+		fmt.Printf("Found use of Unlock for Mutex target %s\n", d.name)
+		s.addMutexUnlock()
+	} else if d.typeof == RWMutex && d.readSide {
+		fmt.Printf("Found use of Unlock for the read side of RWMutex target %s\n", d.name)
+		s.addRWMutexRUnlock()
+	} else if d.typeof == RWMutex {
+		fmt.Printf("Found use of Unlock for RWMutex target %s\n", d.name)
+		s.addRWMutexUnlock()
+	} else if d.typeof == Locker {
+		fmt.Printf("Found use of Unlock for Locker target %s\n", d.name)
+		s.addLockerUnlock()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Unlock\n", target)
+		s.addUnknownUnlock()
+	}
+}
+
+func (s *AnalysisState) addRLock(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addRLockFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to RLock\n", target)
+			s.addUnknownRLock()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to RLock\n", target)
+		s.addUnknownRLock()
+	}
+}
+
+// addRLockFor classifies a call of RLock on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addRLockFor(target string, d Declaration) {
+	if d.typeof == RWMutex {
+		fmt.Printf("Found use of RLock for RWMutex target %s\n", d.name)
+		s.addRWMutexRLock()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to RLock\n", target)
+		s.addUnknownRLock()
+	}
+}
+
+func (s *AnalysisState) addRUnlock(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addRUnlockFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to RUnlock\n", target)
+			s.addUnknownRUnlock()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to RUnlock\n", target)
+		s.addUnknownRUnlock()
+	}
+}
+
+// addRUnlockFor classifies a call of RUnlock on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addRUnlockFor(target string, d Declaration) {
+	if d.typeof == RWMutex {
+		fmt.Printf("Found use of RUnlock for RWMutex target %s\n", d.name)
+		s.addRWMutexRUnlock()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to RUnlock\n", target)
+		s.addUnknownRUnlock()
+	}
+}
+
+func (s *AnalysisState) addRLocker(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addRLockerFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to RLocker\n", target)
+			s.addUnknownRLocker()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to RLocker\n", target)
+		s.addUnknownRLocker()
+	}
+}
+
+// addRLockerFor classifies a call of RLocker on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addRLockerFor(target string, d Declaration) {
+	if d.typeof == RWMutex {
+		fmt.Printf("Found use of RLocker for RWMutex target %s\n", d.name)
+		s.addRWMutexRLocker()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to RLocker\n", target)
+		s.addUnknownRLocker()
+	}
+}
+
+func (s *AnalysisState) addTryLock(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addTryLockFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to TryLock\n", target)
+			s.addUnknownTryLock()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to TryLock\n", target)
+		s.addUnknownTryLock()
+	}
+}
+
+// addTryLockFor classifies a call of TryLock on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addTryLockFor(target string, d Declaration) {
+	if d.typeof == Mutex {
+		fmt.Printf("Found use of TryLock for Mutex target %s\n", d.name)
+		s.addMutexTryLock()
+	} else if d.typeof == RWMutex {
+		fmt.Printf("Found use of TryLock for RWMutex target %s\n", d.name)
+		s.addRWMutexTryLock()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to TryLock\n", target)
+		s.addUnknownTryLock()
+	}
+}
+
+func (s *AnalysisState) addTryRLock(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addTryRLockFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to TryRLock\n", target)
+			s.addUnknownTryRLock()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to TryRLock\n", target)
+		s.addUnknownTryRLock()
+	}
+}
+
+// addTryRLockFor classifies a call of TryRLock on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addTryRLockFor(target string, d Declaration) {
+	if d.typeof == RWMutex {
+		fmt.Printf("Found use of TryRLock for RWMutex target %s\n", d.name)
+		s.addRWMutexTryRLock()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to TryRLock\n", target)
+		s.addUnknownTryRLock()
+	}
+}
+
+func (s *AnalysisState) addSignal(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addSignalFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Signal\n", target)
+			s.addUnknownSignal()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Signal\n", target)
+		s.addUnknownSignal()
+	}
+}
+
+// addSignalFor classifies a call of Signal on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addSignalFor(target string, d Declaration) {
+	if d.typeof == Cond {
+		fmt.Printf("Found use of Signal for Cond target %s\n", d.name)
+		s.addCondSignal()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Signal\n", target)
+		s.addUnknownSignal()
+	}
+}
+
+func (s *AnalysisState) addBroadcast(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addBroadcastFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Broadcast\n", target)
+			s.addUnknownBroadcast()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Broadcast\n", target)
+		s.addUnknownBroadcast()
+	}
+}
+
+// addBroadcastFor classifies a call of Broadcast on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addBroadcastFor(target string, d Declaration) {
+	if d.typeof == Cond {
+		fmt.Printf("Found use of Broadcast for Cond target %s\n", d.name)
+		s.addCondBroadcast()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Broadcast\n", target)
+		s.addUnknownBroadcast()
+	}
+}
+
+func (s *AnalysisState) addDo(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addDoFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Do\n", target)
+			s.addUnknownDo()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Do\n", target)
+		s.addUnknownDo()
+	}
+}
+
+// addDoFor classifies a call of Do on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addDoFor(target string, d Declaration) {
+	if d.typeof == Once {
+		fmt.Printf("Found use of Do for Once target %s\n", d.name)
+		s.addOnceDo()
+	} else if d.typeof == SingleFlight {
+		fmt.Printf("Found use of Do for SingleFlight target %s\n", d.name)
+		s.addSingleFlightDo()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Do\n", target)
+		s.addUnknownDo()
+	}
+}
+
+func (s *AnalysisState) addGo(target string) {
+	vs, ok := s.lookup(target)
+	plausible := s.couldBePrimitive(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addGoFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Go\n", target)
+			s.addUnknownGo()
+		}
+	} else if plausible {
+		fmt.Printf("No match for target %s for call to Go\n", target)
+		s.addUnknownGo()
+	} else {
+		fmt.Printf("Ignoring call of Go on target %s, which is not a primitive\n", target)
+	}
+}
+
+// addGoFor classifies a call of Go on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addGoFor(target string, d Declaration) {
+	if d.typeof == ErrGroup {
+		fmt.Printf("Found use of Go for ErrGroup target %s\n", d.name)
+		s.addErrGroupGo()
+	} else if d.typeof == WaitGroup {
+		fmt.Printf("Found use of Go for WaitGroup target %s\n", d.name)
+		s.addWaitGroupGo()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Go\n", target)
+		s.addUnknownGo()
+	}
+}
+
+func (s *AnalysisState) addTryGo(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addTryGoFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to TryGo\n", target)
+			s.addUnknownTryGo()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to TryGo\n", target)
+		s.addUnknownTryGo()
+	}
+}
+
+// addTryGoFor classifies a call of TryGo on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addTryGoFor(target string, d Declaration) {
+	if d.typeof == ErrGroup {
+		fmt.Printf("Found use of TryGo for ErrGroup target %s\n", d.name)
+		s.addErrGroupTryGo()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to TryGo\n", target)
+		s.addUnknownTryGo()
+	}
+}
+
+func (s *AnalysisState) addSetLimit(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addSetLimitFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to SetLimit\n", target)
+			s.addUnknownSetLimit()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to SetLimit\n", target)
+		s.addUnknownSetLimit()
+	}
+}
+
+// addSetLimitFor classifies a call of SetLimit on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addSetLimitFor(target string, d Declaration) {
+	if d.typeof == ErrGroup {
+		fmt.Printf("Found use of SetLimit for ErrGroup target %s\n", d.name)
+		s.addErrGroupSetLimit()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to SetLimit\n", target)
+		s.addUnknownSetLimit()
+	}
+}
+
+func (s *AnalysisState) addAcquire(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addAcquireFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Acquire\n", target)
+			s.addUnknownAcquire()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Acquire\n", target)
+		s.addUnknownAcquire()
+	}
+}
+
+// addAcquireFor classifies a call of Acquire on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addAcquireFor(target string, d Declaration) {
+	if d.typeof == Semaphore {
+		fmt.Printf("Found use of Acquire for Semaphore target %s\n", d.name)
+		s.addSemAcquire()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Acquire\n", target)
+		s.addUnknownAcquire()
+	}
+}
+
+func (s *AnalysisState) addTryAcquire(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addTryAcquireFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to TryAcquire\n", target)
+			s.addUnknownTryAcq()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to TryAcquire\n", target)
+		s.addUnknownTryAcq()
+	}
+}
+
+// addTryAcquireFor classifies a call of TryAcquire on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addTryAcquireFor(target string, d Declaration) {
+	if d.typeof == Semaphore {
+		fmt.Printf("Found use of TryAcquire for Semaphore target %s\n", d.name)
+		s.addSemTryAcquire()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to TryAcquire\n", target)
+		s.addUnknownTryAcq()
+	}
+}
+
+func (s *AnalysisState) addRelease(target string) {
+	vs, ok := s.lookup(target)
+	plausible := s.couldBePrimitive(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addReleaseFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Release\n", target)
+			s.addUnknownRelease()
+		}
+	} else if plausible {
+		fmt.Printf("No match for target %s for call to Release\n", target)
+		s.addUnknownRelease()
+	} else {
+		fmt.Printf("Ignoring call of Release on target %s, which is not a primitive\n", target)
+	}
+}
+
+// addReleaseFor classifies a call of Release on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addReleaseFor(target string, d Declaration) {
+	if d.typeof == Semaphore {
+		fmt.Printf("Found use of Release for Semaphore target %s\n", d.name)
+		s.addSemRelease()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Release\n", target)
+		s.addUnknownRelease()
+	}
+}
+
+func (s *AnalysisState) addDoChan(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addDoChanFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to DoChan\n", target)
+			s.addUnknownDoChan()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to DoChan\n", target)
+		s.addUnknownDoChan()
+	}
+}
+
+// addDoChanFor classifies a call of DoChan on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addDoChanFor(target string, d Declaration) {
+	if d.typeof == SingleFlight {
+		fmt.Printf("Found use of DoChan for SingleFlight target %s\n", d.name)
+		s.addSingleFlightChan()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to DoChan\n", target)
+		s.addUnknownDoChan()
+	}
+}
+
+func (s *AnalysisState) addForget(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addForgetFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Forget\n", target)
+			s.addUnknownForget()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Forget\n", target)
+		s.addUnknownForget()
+	}
+}
+
+// addForgetFor classifies a call of Forget on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addForgetFor(target string, d Declaration) {
+	if d.typeof == SingleFlight {
+		fmt.Printf("Found use of Forget for SingleFlight target %s\n", d.name)
+		s.addSingleFlightFgt()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Forget\n", target)
+		s.addUnknownForget()
+	}
+}
+
+func (s *AnalysisState) addParallel(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addParallelFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Parallel\n", target)
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Parallel\n", target)
+	}
+}
+
+// addParallelFor classifies a call of Parallel on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addParallelFor(target string, d Declaration) {
+	if d.typeof == TestingT {
+		fmt.Printf("Found use of Parallel for %s target %s\n", d.typeof.String(), d.name)
+		s.addTestParallel()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Parallel\n", target)
+	}
+}
+
+func (s *AnalysisState) addRunParallel(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addRunParallelFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to RunParallel\n", target)
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to RunParallel\n", target)
+	}
+}
+
+// addRunParallelFor classifies a call of RunParallel on target, which
+// was resolved to declaration d.
+func (s *AnalysisState) addRunParallelFor(target string, d Declaration) {
+	if d.typeof == TestingB {
+		fmt.Printf("Found use of RunParallel for %s target %s\n", d.typeof.String(), d.name)
+		s.addBenchRunParallel()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to RunParallel\n", target)
+	}
+}
+
+func (s *AnalysisState) addSetParallelism(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addSetParallelismFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to SetParallelism\n", target)
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to SetParallelism\n", target)
+	}
+}
+
+// addSetParallelismFor classifies a call of SetParallelism on target,
+// which was resolved to declaration d.
+func (s *AnalysisState) addSetParallelismFor(target string, d Declaration) {
+	if d.typeof == TestingB {
+		fmt.Printf("Found use of SetParallelism for %s target %s\n", d.typeof.String(), d.name)
+		s.addBenchSetParallel()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to SetParallelism\n", target)
+	}
+}
+
+func (s *AnalysisState) addNext(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addNextFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Next\n", target)
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Next\n", target)
+	}
+}
+
+// addNextFor classifies a call of Next on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addNextFor(target string, d Declaration) {
+	if d.typeof == TestingPB {
+		fmt.Printf("Found use of Next for %s target %s\n", d.typeof.String(), d.name)
+		s.addPbNext()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Next\n", target)
+	}
+}
+
+func (s *AnalysisState) addStop(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addStopFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Stop\n", target)
+			s.addUnknownStop()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Stop\n", target)
+		s.addUnknownStop()
+	}
+}
+
+// addStopFor classifies a call of Stop on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addStopFor(target string, d Declaration) {
+	if d.typeof == Timer {
+		fmt.Printf("Found use of Stop for Timer target %s\n", d.name)
+		s.addTimerStop()
+	} else if d.typeof == Ticker {
+		fmt.Printf("Found use of Stop for Ticker target %s\n", d.name)
+		s.addTickerStop()
+		s.stoppedTickers[d] = true
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Stop\n", target)
+		s.addUnknownStop()
+	}
+}
+
+func (s *AnalysisState) addReset(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addResetFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Reset\n", target)
+			s.addUnknownReset()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Reset\n", target)
+		s.addUnknownReset()
+	}
+}
+
+// addResetFor classifies a call of Reset on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addResetFor(target string, d Declaration) {
+	if d.typeof == Timer {
+		fmt.Printf("Found use of Reset for Timer target %s\n", d.name)
+		s.addTimerReset()
+	} else if d.typeof == Ticker {
+		fmt.Printf("Found use of Reset for Ticker target %s\n", d.name)
+		s.addTickerReset()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Reset\n", target)
+		s.addUnknownReset()
+	}
+}
+
+func (s *AnalysisState) addLoad(target string) {
+	vs, ok := s.lookup(target)
+	plausible := s.couldBePrimitive(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addLoadFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Load\n", target)
+			s.addUnknownLoad()
+		}
+	} else if plausible {
+		fmt.Printf("No match for target %s for call to Load\n", target)
+		s.addUnknownLoad()
+	} else {
+		fmt.Printf("Ignoring call of Load on target %s, which is not a primitive\n", target)
+	}
+}
+
+// addLoadFor classifies a call of Load on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addLoadFor(target string, d Declaration) {
+	if d.typeof == AtomicValue {
+		fmt.Printf("Found use of Load for AtomicValue target %s\n", d.name)
+		s.addAtomicValueLoad()
+	} else if d.typeof == AtomicTyped {
+		fmt.Printf("Found use of Load for AtomicTyped target %s\n", d.name)
+		s.addAtomicTypedLoad()
+	} else if d.typeof == SyncMap {
+		fmt.Printf("Found use of Load for SyncMap target %s\n", d.name)
+		s.addSyncMapLoad()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Load\n", target)
+		s.addUnknownLoad()
+	}
+}
+
+func (s *AnalysisState) addStore(target string) {
+	vs, ok := s.lookup(target)
+	plausible := s.couldBePrimitive(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addStoreFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Store\n", target)
+			s.addUnknownStore()
+		}
+	} else if plausible {
+		fmt.Printf("No match for target %s for call to Store\n", target)
+		s.addUnknownStore()
+	} else {
+		fmt.Printf("Ignoring call of Store on target %s, which is not a primitive\n", target)
+	}
+}
+
+// addStoreFor classifies a call of Store on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addStoreFor(target string, d Declaration) {
+	if d.typeof == AtomicValue {
+		fmt.Printf("Found use of Store for AtomicValue target %s\n", d.name)
+		s.addAtomicValueStore()
+	} else if d.typeof == AtomicTyped {
+		fmt.Printf("Found use of Store for AtomicTyped target %s\n", d.name)
+		s.addAtomicTypedStore()
+	} else if d.typeof == SyncMap {
+		fmt.Printf("Found use of Store for SyncMap target %s\n", d.name)
+		s.addSyncMapStore()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Store\n", target)
+		s.addUnknownStore()
+	}
+}
+
+func (s *AnalysisState) addSwap(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addSwapFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Swap\n", target)
+			s.addUnknownSwap()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to Swap\n", target)
+		s.addUnknownSwap()
+	}
+}
+
+// addSwapFor classifies a call of Swap on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addSwapFor(target string, d Declaration) {
+	if d.typeof == AtomicValue {
+		fmt.Printf("Found use of Swap for AtomicValue target %s\n", d.name)
+		s.addAtomicValueSwap()
+	} else if d.typeof == AtomicTyped {
+		fmt.Printf("Found use of Swap for AtomicTyped target %s\n", d.name)
+		s.addAtomicTypedSwap()
+	} else if d.typeof == SyncMap {
+		fmt.Printf("Found use of Swap for SyncMap target %s\n", d.name)
+		s.addSyncMapSwap()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Swap\n", target)
+		s.addUnknownSwap()
+	}
+}
+
+func (s *AnalysisState) addCompareAndSwap(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addCompareAndSwapFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to CompareAndSwap\n", target)
+			s.addUnknownCAS()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to CompareAndSwap\n", target)
+		s.addUnknownCAS()
+	}
+}
+
+// addCompareAndSwapFor classifies a call of CompareAndSwap on target,
+// which was resolved to declaration d.
+func (s *AnalysisState) addCompareAndSwapFor(target string, d Declaration) {
+	if d.typeof == AtomicValue {
+		fmt.Printf("Found use of CompareAndSwap for AtomicValue target %s\n", d.name)
+		s.addAtomicValueCAS()
+	} else if d.typeof == AtomicTyped {
+		fmt.Printf("Found use of CompareAndSwap for AtomicTyped target %s\n", d.name)
+		s.addAtomicTypedCAS()
+	} else if d.typeof == SyncMap {
+		fmt.Printf("Found use of CompareAndSwap for SyncMap target %s\n", d.name)
+		s.addSyncMapCAS()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to CompareAndSwap\n", target)
+		s.addUnknownCAS()
+	}
+}
+
+func (s *AnalysisState) addLoadOrStore(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addLoadOrStoreFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to LoadOrStore\n", target)
+			s.addUnknownLoadStore()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to LoadOrStore\n", target)
+		s.addUnknownLoadStore()
+	}
+}
+
+// addLoadOrStoreFor classifies a call of LoadOrStore on target, which
+// was resolved to declaration d.
+func (s *AnalysisState) addLoadOrStoreFor(target string, d Declaration) {
+	if d.typeof == SyncMap {
+		fmt.Printf("Found use of LoadOrStore for SyncMap target %s\n", d.name)
+		s.addSyncMapLoadStore()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to LoadOrStore\n", target)
+		s.addUnknownLoadStore()
+	}
+}
+
+func (s *AnalysisState) addLoadAndDelete(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addLoadAndDeleteFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to LoadAndDelete\n", target)
+			s.addUnknownLoadDel()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to LoadAndDelete\n", target)
+		s.addUnknownLoadDel()
+	}
+}
+
+// addLoadAndDeleteFor classifies a call of LoadAndDelete on target,
+// which was resolved to declaration d.
+func (s *AnalysisState) addLoadAndDeleteFor(target string, d Declaration) {
+	if d.typeof == SyncMap {
+		fmt.Printf("Found use of LoadAndDelete for SyncMap target %s\n", d.name)
+		s.addSyncMapLoadDel()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to LoadAndDelete\n", target)
+		s.addUnknownLoadDel()
+	}
+}
+
+func (s *AnalysisState) addDelete(target string) {
+	vs, ok := s.lookup(target)
+	plausible := s.couldBePrimitive(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addDeleteFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Delete\n", target)
+			s.addUnknownDelete()
+		}
+	} else if plausible {
+		fmt.Printf("No match for target %s for call to Delete\n", target)
+		s.addUnknownDelete()
+	} else {
+		fmt.Printf("Ignoring call of Delete on target %s, which is not a primitive\n", target)
+	}
+}
+
+// addDeleteFor classifies a call of Delete on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addDeleteFor(target string, d Declaration) {
+	if d.typeof == SyncMap {
+		fmt.Printf("Found use of Delete for SyncMap target %s\n", d.name)
+		s.addSyncMapDelete()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Delete\n", target)
+		s.addUnknownDelete()
+	}
+}
+
+func (s *AnalysisState) addRangeCall(target string) {
+	vs, ok := s.lookup(target)
+	plausible := s.couldBePrimitive(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addRangeCallFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Range\n", target)
+			s.addUnknownRange()
+		}
+	} else if plausible {
+		fmt.Printf("No match for target %s for call to Range\n", target)
+		s.addUnknownRange()
+	} else {
+		fmt.Printf("Ignoring call of Range on target %s, which is not a primitive\n", target)
+	}
+}
+
+// addRangeCallFor classifies a call of Range on target, which was
+// resolved to declaration d.
+func (s *AnalysisState) addRangeCallFor(target string, d Declaration) {
+	if d.typeof == SyncMap {
+		fmt.Printf("Found use of Range for SyncMap target %s\n", d.name)
+		s.addSyncMapRange()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Range\n", target)
+		s.addUnknownRange()
+	}
+}
+
+func (s *AnalysisState) addGet(target string) {
+	vs, ok := s.lookup(target)
+	plausible := s.couldBePrimitive(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addGetFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Get\n", target)
+			s.addUnknownGet()
+		}
+	} else if plausible {
+		fmt.Printf("No match for target %s for call to Get\n", target)
+		s.addUnknownGet()
+	} else {
+		fmt.Printf("Ignoring call of Get on target %s, which is not a primitive\n", target)
+	}
+}
+
+// addGetFor classifies a call of Get on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addGetFor(target string, d Declaration) {
+	if d.typeof == Pool {
+		fmt.Printf("Found use of Get for Pool target %s\n", d.name)
+		s.addPoolGet()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Get\n", target)
+		s.addUnknownGet()
+	}
+}
+
+func (s *AnalysisState) addPut(target string) {
+	vs, ok := s.lookup(target)
+	plausible := s.couldBePrimitive(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			s.addPutFor(target, vs[0])
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to Put\n", target)
+			s.addUnknownPut()
+		}
+	} else if plausible {
+		fmt.Printf("No match for target %s for call to Put\n", target)
+		s.addUnknownPut()
+	} else {
+		fmt.Printf("Ignoring call of Put on target %s, which is not a primitive\n", target)
+	}
+}
+
+// addPutFor classifies a call of Put on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addPutFor(target string, d Declaration) {
+	if d.typeof == Pool {
+		fmt.Printf("Found use of Put for Pool target %s\n", d.name)
+		s.addPoolPut()
+	} else {
+		fmt.Printf("Unexpected match for target %s for call to Put\n", target)
+		s.addUnknownPut()
+	}
+}
+
+// Assignments to fields named New are common, so assignments that do
+// not match a Pool are not counted.
+func (s *AnalysisState) addNewAssign(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok && len(vs) == 1 && vs[0].typeof == Pool {
+		fmt.Printf("Found assignment of New for Pool target %s\n", vs[0].name)
+		s.addPoolNew()
+	} else {
+		fmt.Printf("No single Pool match for target %s for assignment to New\n", target)
+	}
+}
+
+// Err is a common method name on types unrelated to concurrency, so
+// calls that do not match a Context are not counted.
+func (s *AnalysisState) addErr(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok && len(vs) == 1 {
+		s.addErrFor(target, vs[0])
+	} else {
+		fmt.Printf("No single Context match for target %s for call to Err\n", target)
+	}
+}
+
+// addErrFor classifies a call of Err on target, which was resolved to
+// declaration d.
+func (s *AnalysisState) addErrFor(target string, d Declaration) {
+	if d.typeof == Context {
+		fmt.Printf("Found use of Err for Context target %s\n", d.name)
+		s.addCtxErr()
+	} else {
+		fmt.Printf("No Context match for target %s for call to Err\n", target)
+	}
+}
+
+func (s *AnalysisState) addSend(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			if vs[0].typeof == Chan {
+				fmt.Printf("Found send on Chan target %s\n", vs[0].name)
+				s.addChanSend()
+			} else {
+				fmt.Printf("Unexpected match for target %s for send\n", target)
+				s.addUnknownSend()
+			}
+		} else {
+			fmt.Printf("Multiple matches for target %s for send\n", target)
+			s.addUnknownSend()
+		}
+	} else {
+		fmt.Printf("No match for target %s for send\n", target)
+		s.addUnknownSend()
+	}
+}
+
+func (s *AnalysisState) addRecv(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			if vs[0].typeof == Chan {
+				fmt.Printf("Found receive on Chan target %s\n", vs[0].name)
+				s.addChanRecv()
+			} else {
+				fmt.Printf("Unexpected match for target %s for receive\n", target)
+				s.addUnknownRecv()
+			}
+		} else {
+			fmt.Printf("Multiple matches for target %s for receive\n", target)
+			s.addUnknownRecv()
+		}
+	} else {
+		fmt.Printf("No match for target %s for receive\n", target)
+		s.addUnknownRecv()
+	}
+}
+
+// addDoneRecv handles receives of the form <-target.Done(), where the
+// channel is the one returned by the Done method of a Context.
+func (s *AnalysisState) addDoneRecv(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok {
+		if len(vs) == 1 {
+			if vs[0].typeof == Context {
+				fmt.Printf("Found receive on Done channel of Context target %s\n", vs[0].name)
+				s.addChanRecv()
+			} else {
+				fmt.Printf("Unexpected match for target %s for receive from Done\n", target)
+				s.addUnknownRecv()
+			}
+		} else {
+			fmt.Printf("Multiple matches for target %s for receive from Done\n", target)
+			s.addUnknownRecv()
+		}
+	} else {
+		fmt.Printf("No match for target %s for receive from Done\n", target)
+		s.addUnknownRecv()
+	}
+}
+
+// The receive itself is counted by addRecv, so a comma-ok receive that
+// cannot be matched to a channel is already counted as an unknown receive.
+func (s *AnalysisState) addRecvOk(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok && len(vs) == 1 && vs[0].typeof == Chan {
+		fmt.Printf("Found comma-ok receive on Chan target %s\n", vs[0].name)
+		s.addChanRecvOk()
+	} else {
+		fmt.Printf("No single Chan match for target %s for comma-ok receive\n", target)
+	}
+}
+
+// Ranging over an unmatched target is not counted, since most range
+// loops are over slices and maps rather than channels.
+func (s *AnalysisState) addRange(target string) {
+	vs, ok := s.lookup(target)
+	target = splitTarget(target)
+	if ok && len(vs) == 1 && vs[0].typeof == Chan {
+		fmt.Printf("Found range over Chan target %s\n", vs[0].name)
+		s.addChanRange()
+	} else {
+		fmt.Printf("No single Chan match for target %s for range\n", target)
+	}
+}
+
+func (s *AnalysisState) addClose(target string) {
+	vs, ok := s.lookup(target)
 	target = splitTarget(target)
-	vs, ok := s.decls[target]
 	if ok {
-		if len(vs) == 1 {
-			if vs[0].typeof == Cond {
-				fmt.Printf("Found use of Broadcast for Cond target %s\n", vs[0].name)
-				s.addCondBroadcast()
-			} else {
-				fmt.Printf("Unexpected match for target %s for call to Broadcast\n", target)
-				s.addUnknownBroadcast()
+		if len(vs) == 1 {
+			if vs[0].typeof == Chan {
+				fmt.Printf("Found close of Chan target %s\n", vs[0].name)
+				s.addChanClose()
+			} else {
+				fmt.Printf("Unexpected match for target %s for call to close\n", target)
+				s.addUnknownClose()
+			}
+		} else {
+			fmt.Printf("Multiple matches for target %s for call to close\n", target)
+			s.addUnknownClose()
+		}
+	} else {
+		fmt.Printf("No match for target %s for call to close\n", target)
+		s.addUnknownClose()
+	}
+}
+
+func main() {
+	var filePath string
+	flag.StringVar(&filePath, "filePath", "", "The file to be processed")
+
+	var dirPath string
+	flag.StringVar(&dirPath, "dirPath", "", "The directory to be processed")
+
+	var outputFile string
+	flag.StringVar(&outputFile, "output", "", "The CSV file to be created")
+
+	var options Options
+	flag.BoolVar(&options.perInstantiation, "perInstantiation", false,
+		"Count the primitives of a generic type again for each instantiation")
+	flag.BoolVar(&options.typeCheck, "typeCheck", false,
+		"Classify calls by the type of their receiver using go/types")
+
+	flag.Parse()
+	if options.typeCheck {
+		options.importer = newOfflineImporter()
+	}
+
+	if outputFile != "" {
+		csvFile, err := os.Create(outputFile)
+		if err == nil {
+			writer := csv.NewWriter(csvFile)
+			if err := writer.Write(stateHeaders()); err != nil {
+				log.Fatalln("Error writing CSV file", err)
+			}
+			defer writer.Flush()
+			if dirPath != "" {
+				fmt.Printf("Processing all go files in directory %s\n", dirPath)
+				processDir(dirPath, writer, options)
+			} else if filePath != "" {
+				fmt.Printf("Processing file %s\n", filePath)
+				processFile(filePath, writer, options)
+			} else {
+				fmt.Print("No file or directory given\n")
+			}
+
+			csvFile.Close()
+		} else {
+			log.Fatalln("Error creating CSV file", err)
+		}
+	} else {
+		fmt.Print("No output file given\n")
+	}
+
+}
+
+func processDir(dirPath string, writer *csv.Writer, options Options) {
+	var paths []string
+	var err = filepath.Walk(dirPath, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			fmt.Printf("Encountered an error accessing path %q: %v\n", path, err)
+			return err
+		} else {
+			if filepath.Ext(path) == ".go" {
+				paths = append(paths, path)
+				return nil
+			} else {
+				return nil
+			}
+		}
+	})
+
+	if err != nil {
+		fmt.Printf("Encountered an error walking the directory tree %q: %v", dirPath, err)
+	}
+
+	// The files of each directory are analyzed together, and their rows
+	// are written as soon as the directory is done, in the order the
+	// files were found
+	var dirs []string
+	dirPaths := map[string][]string{}
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if _, ok := dirPaths[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		dirPaths[dir] = append(dirPaths[dir], path)
+	}
+	for _, dir := range dirs {
+		rows := processPackages(dirPaths[dir], options)
+		for _, path := range dirPaths[dir] {
+			row, ok := rows[path]
+			if ok {
+				if err := writer.Write(row); err != nil {
+					log.Fatalln("Error writing CSV file", err)
+				}
+			}
+		}
+		writer.Flush()
+	}
+}
+
+// offlineImporter imports the packages of the standard library and of
+// the module of the importing file from source, and fails for any other
+// package, so type checking works offline.
+type offlineImporter struct {
+	source types.ImporterFrom
+	fset   *token.FileSet
+	// The packages imported from modules, by directory
+	packages map[string]*types.Package
+}
+
+func newOfflineImporter() *offlineImporter {
+	return &offlineImporter{source: importer.ForCompiler(token.NewFileSet(), "source", nil).(types.ImporterFrom),
+		fset: token.NewFileSet(), packages: map[string]*types.Package{}}
+}
+
+func (i *offlineImporter) Import(path string) (*types.Package, error) {
+	return i.ImportFrom(path, "", 0)
+}
+
+func (i *offlineImporter) ImportFrom(path string, dir string, mode types.ImportMode) (*types.Package, error) {
+	if dir != "" {
+		pkgDir := findModule(dir).importDir(path)
+		if pkgDir != "" {
+			return i.importModulePackage(path, pkgDir)
+		}
+	}
+	first := strings.Split(path, "/")[0]
+	if strings.Contains(first, ".") || path == "C" {
+		return nil, fmt.Errorf("package %s is not in the standard library or the module", path)
+	}
+	return i.source.ImportFrom(path, dir, mode)
+}
+
+// importModulePackage type checks the package with the given import path
+// in dir. Type errors are ignored, like in the packages being analyzed.
+func (i *offlineImporter) importModulePackage(path string, dir string) (*types.Package, error) {
+	pkg, ok := i.packages[dir]
+	if ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle through package %s", path)
+		}
+		return pkg, nil
+	}
+	i.packages[dir] = nil
+	files := parsePackageDir(i.fset, dir)
+	if len(files) == 0 {
+		delete(i.packages, dir)
+		return nil, fmt.Errorf("no Go files for package %s in %s", path, dir)
+	}
+	conf := types.Config{Importer: i, Error: func(err error) {}}
+	pkg, _ = conf.Check(path, i.fset, files, nil)
+	fmt.Printf("Type checked module package %s\n", path)
+	i.packages[dir] = pkg
+	return pkg, nil
+}
+
+// typeCheckPackage type checks the files of a package. Type errors are
+// ignored, so the information is partial for code that does not type
+// check.
+func typeCheckPackage(fset *token.FileSet, name string, files []*ast.File, imp types.Importer) *types.Info {
+	info := &types.Info{Selections: map[*ast.SelectorExpr]*types.Selection{}}
+	errors := 0
+	conf := types.Config{Importer: imp, Error: func(err error) {
+		errors++
+	}}
+	conf.Check(name, fset, files, info)
+	fmt.Printf("Type checked package %s in %d files with %d errors\n", name, len(files), errors)
+	return info
+}
+
+// processFile analyzes the file at filePath together with the other
+// files of its package in the same directory, and writes its row. Test
+// files and files with another package clause are left out.
+func processFile(filePath string, writer *csv.Writer, options Options) {
+	paths := []string{filePath}
+	dir := filepath.Dir(filePath)
+	name := packageClause(filePath)
+	entries, err := os.ReadDir(dir)
+	if err == nil && name != "" {
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if entry.IsDir() || filepath.Ext(path) != ".go" || strings.HasSuffix(path, "_test.go") ||
+				path == filepath.Clean(filePath) {
+				continue
+			}
+			if packageClause(path) == name {
+				paths = append(paths, path)
+			}
+		}
+	}
+	row, ok := processPackages(paths, options)[filePath]
+	if ok {
+		if err := writer.Write(row); err != nil {
+			log.Fatalln("Error writing CSV file", err)
+		}
+		writer.Flush()
+	}
+}
+
+// packageClause returns the package name of the file at path, or "" if
+// it cannot be parsed.
+func packageClause(path string) string {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return file.Name.Name
+}
+
+// processPackages analyzes the files at paths, which are in the same
+// directory. Files with the same package clause form a package and are
+// analyzed together, so that the package-level declarations of one file
+// resolve the uses in the others. It returns the row of each file.
+func processPackages(paths []string, options Options) map[string][]string {
+	if options.typeCheck && options.importer == nil {
+		options.importer = newOfflineImporter()
+	}
+	fset := token.NewFileSet()
+	var names []string
+	files := map[string][]*ast.File{}
+	filePaths := map[string][]string{}
+	for _, path := range paths {
+		fmt.Printf("Processing file %s\n", path)
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			log.Printf("Could not process file %s", path)
+			log.Print(err)
+			continue
+		}
+		name := file.Name.Name
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+		files[name] = append(files[name], file)
+		filePaths[name] = append(filePaths[name], path)
+	}
+
+	rows := map[string][]string{}
+	for _, name := range names {
+		processPackage(fset, name, files[name], filePaths[name], options, rows)
+	}
+	return rows
+}
+
+func newAnalysisState(filePath string, module Module, options Options) *AnalysisState {
+	return &AnalysisState{decls: map[string][]Declaration{}, imports: map[string]string{},
+		embeds: map[string][]DeclType{}, embeddedTypes: map[string][]string{}, typedVars: map[string][]TypedVar{},
+		stoppedTickers: map[Declaration]bool{}, goVersion: module.goVersion,
+		testFile: strings.HasSuffix(filePath, "_test.go"), generics: map[string][]Declaration{},
+		instantiations: map[string]bool{}, condLockers: map[string][]CondLocker{},
+		aliases: map[string][]Alias{}, aliasFuncs: map[string][]AliasFunc{}, locals: map[string][]LocalVar{},
+		methodValues: map[*ast.SelectorExpr]bool{}, methodCalls: map[*ast.SelectorExpr]bool{},
+		modulePackages: map[string]PackageDecls{}, options: options}
+}
+
+func processPackage(fset *token.FileSet, name string, files []*ast.File, paths []string, options Options, rows map[string][]string) {
+	var info *types.Info
+	if options.typeCheck {
+		info = typeCheckPackage(fset, name, files, options.importer)
+	}
+	dir := filepath.Dir(paths[0])
+	module := findModule(dir)
+
+	absDir, _ := filepath.Abs(dir)
+
+	// The package-level declarations of all the files are merged once
+	// into the package table, which the file states look names up in
+	states := make([]*AnalysisState, len(files))
+	modulePackages := map[string]PackageDecls{}
+	pkg := newAnalysisState(dir, module, options)
+	pkg.modulePackages = modulePackages
+	for i, file := range files {
+		states[i] = newAnalysisState(paths[i], module, options)
+		states[i].info = info
+		states[i].modulePackages = modulePackages
+		declVisitor := &Visitor{fset: fset, mode: true, state: states[i]}
+		ast.Walk(declVisitor, file)
+		states[i].checkCondLockers()
+		pkg.addPackageDecls(states[i].packageDecls())
+		pkg.addModuleTypes(states[i], module, absDir, options)
+	}
+	pkg.resolveEmbeddedDecls()
+
+	for i, fileState := range states {
+		fileState.pkg = pkg
+		fileState.addModuleImports(module, absDir, options)
+		fileState.resolveEmbeddedDecls()
+		fileState.resolveAliases()
+		usesVisitor := &Visitor{fset: fset, mode: false, state: fileState}
+		ast.Walk(usesVisitor, files[i])
+		fileState.checkTickers()
+		rows[paths[i]] = fileState.stateToSlice(paths[i])
+		states[i] = nil
+	}
+}
+
+type Visitor struct {
+	fset  *token.FileSet
+	mode  bool
+	state *AnalysisState
+	// The number of enclosing loops in the current function, for the
+	// node currently being visited
+	loopDepth int
+	// The kind of the enclosing test function, "Test" or "Benchmark",
+	// or "" outside of tests and benchmarks
+	testFunc string
+	// The innermost scope enclosing the node being visited, and whether
+	// the node is inside a struct type
+	scope    Scope
+	inStruct bool
+}
+
+// getTypedDecl classifies the receiver of the method call x by its type
+// when the file was type checked. It returns false if the file was not
+// type checked or the type of the receiver is unknown, in which case
+// the call is resolved by name instead. A receiver whose type is known
+// but not a primitive has the Unknown type, and the call is ignored.
+func (v *Visitor) getTypedDecl(x *ast.SelectorExpr) (Declaration, bool) {
+	if v.state.info == nil {
+		return Declaration{}, false
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, x.X)
+	target := buf.String()
+
+	// c.L.Lock() is a call on the Locker of a Cond, which is counted
+	// for the Cond
+	field, ok := x.X.(*ast.SelectorExpr)
+	if ok && field.Sel.Name == "L" {
+		sel, ok := v.state.info.Selections[field]
+		if ok && sel.Kind() == types.FieldVal && isTypesObject(sel.Obj(), "sync", "L") {
+			fmt.Printf("Found %s with receiver type Cond\n", target)
+			v.state.addTypedCalls()
+			return createDecl(splitTarget(target[:len(target)-2]), Cond), true
+		}
+	}
+
+	sel, ok := v.state.info.Selections[x]
+	if !ok {
+		// Only method calls fall back, not package functions that
+		// happen to have the name of a method
+		if v.state.methodCalls[x] {
+			fmt.Printf("Unknown receiver type for %s, resolving by name\n", target)
+			v.state.addTypeFallbacks()
+		}
+		return Declaration{}, false
+	} else if sel.Kind() != types.MethodVal {
+		fmt.Printf("Ignoring %s.%s, which is not a method\n", target, x.Sel.Name)
+		return Declaration{typeof: Unknown}, true
+	}
+	typeof := Unknown
+	recv := sel.Obj().Type().(*types.Signature).Recv()
+	if recv != nil {
+		typeof = getTypesDeclType(recv.Type())
+	}
+	if typeof == Unknown {
+		fmt.Printf("Ignoring %s.%s, whose receiver type is not a primitive\n", target, x.Sel.Name)
+		return Declaration{typeof: Unknown}, true
+	}
+	// A Locker may be an alias of a primitive, such as a parameter
+	// bound to &mu
+	if typeof == Locker {
+		vs, ok := v.state.lookup(target)
+		if ok && len(vs) == 1 && vs[0].typeof != Locker {
+			v.state.addTypedCalls()
+			return vs[0], true
+		}
+	}
+	fmt.Printf("Found %s with receiver type %s\n", target, typeof.String())
+	v.state.addTypedCalls()
+	// The declaration of the receiver, if it has the same type, keeps
+	// the calls of a ticker attributed to the ticker it stops
+	vs, ok := v.state.lookupDecls(target)
+	if ok && len(vs) == 1 && vs[0].typeof == typeof {
+		return vs[0], true
+	}
+	return createDecl(splitTarget(target), typeof), true
+}
+
+func isTypesObject(obj types.Object, path string, name string) bool {
+	return obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == path && obj.Name() == name
+}
+
+// getTypesDeclType returns the declaration type of the primitive t, or
+// the primitive t points to, or Unknown if t is not a primitive.
+func getTypesDeclType(t types.Type) DeclType {
+	ptr, ok := t.(*types.Pointer)
+	if ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return Unknown
+	}
+	name := named.Obj().Name()
+	switch named.Obj().Pkg().Path() {
+	case "sync":
+		switch name {
+		case "WaitGroup":
+			return WaitGroup
+		case "Cond":
+			return Cond
+		case "Once":
+			return Once
+		case "Mutex":
+			return Mutex
+		case "RWMutex":
+			return RWMutex
+		case "Locker":
+			return Locker
+		case "Map":
+			return SyncMap
+		case "Pool":
+			return Pool
+		}
+	case "sync/atomic":
+		if name == "Value" {
+			return AtomicValue
+		}
+		return AtomicTyped
+	case "context":
+		if name == "Context" {
+			return Context
+		}
+	case "time":
+		switch name {
+		case "Timer":
+			return Timer
+		case "Ticker":
+			return Ticker
+		}
+	case "testing":
+		switch name {
+		case "T":
+			return TestingT
+		case "B":
+			return TestingB
+		case "PB":
+			return TestingPB
+		}
+	case "golang.org/x/sync/errgroup":
+		return ErrGroup
+	case "golang.org/x/sync/semaphore":
+		return Semaphore
+	case "golang.org/x/sync/singleflight":
+		return SingleFlight
+	}
+	return Unknown
+}
+
+func (v *Visitor) addDef(d Declaration) {
+	d.scope = v.scope
+	d.field = d.field || v.inStruct
+	v.state.addDecl(d)
+}
+
+func (v *Visitor) addTypedVar(name string, typeName string) {
+	v.state.addTypedVar(name, TypedVar{typeName: typeName, scope: v.scope, field: v.inStruct})
+}
+
+// enterScope returns the visitor used for the children of node n, which
+// opens a new scope. The fields of a struct type are visible in the
+// whole file, since they are reached through selectors.
+func (v *Visitor) enterScope(n ast.Node) *Visitor {
+	child := *v
+	if _, ok := n.(*ast.StructType); ok {
+		child.scope = Scope{}
+		child.inStruct = true
+	} else {
+		child.scope = Scope{pos: n.Pos(), end: n.End()}
+		child.inStruct = false
+	}
+	return &child
+}
+
+// enterLoop returns the visitor used for the body of a loop.
+func (v *Visitor) enterLoop() *Visitor {
+	return &Visitor{fset: v.fset, mode: v.mode, state: v.state, loopDepth: v.loopDepth + 1,
+		testFunc: v.testFunc}
+}
+
+// enterFuncLit returns the visitor used for the body of a function
+// literal, which does not run in the loops enclosing the literal.
+func (v *Visitor) enterFuncLit() *Visitor {
+	return &Visitor{fset: v.fset, mode: v.mode, state: v.state, testFunc: v.testFunc}
+}
+
+// enterTestFunc returns the visitor used for the body of a test function
+// of the given kind.
+func (v *Visitor) enterTestFunc(kind string) *Visitor {
+	return &Visitor{fset: v.fset, mode: v.mode, state: v.state, testFunc: kind}
+}
+
+// isLoopVar checks if the identifier id refers to a loop variable,
+// resolving it through the scopes of the file.
+func (v *Visitor) isLoopVar(id *ast.Ident) bool {
+	l, ok := v.state.resolveLocal(id.Name, id.Pos())
+	return ok && l.loopVar
+}
+
+// addLocals records the local variables or parameters declared by the
+// identifiers in names, in the current scope. The type of a variable is
+// known if typ is given, or if its value in values has a known type.
+func (v *Visitor) addLocals(names []ast.Expr, values []ast.Expr, typ ast.Expr, loopVar bool) {
+	if v.scope.end == token.NoPos || v.inStruct {
+		return
+	}
+	for i, name := range names {
+		id, ok := name.(*ast.Ident)
+		if ok && id.Name != "_" {
+			typed := typ != nil || (len(values) == len(names) && isTypedValue(values[i]))
+			v.state.addLocal(id.Name, LocalVar{scope: v.scope, pos: id.Pos(), loopVar: loopVar, typed: typed})
+		}
+	}
+}
+
+// isTypedValue checks if the type of e is given by e itself, as for a
+// literal, a pointer to a composite literal, new(T) or make(T).
+func isTypedValue(e ast.Expr) bool {
+	e = unparen(e)
+	unary, ok := e.(*ast.UnaryExpr)
+	if ok && unary.Op == token.AND {
+		e = unary.X
+	}
+	switch x := e.(type) {
+	case *ast.CompositeLit, *ast.BasicLit, *ast.FuncLit:
+		return true
+	case *ast.CallExpr:
+		id, ok := x.Fun.(*ast.Ident)
+		return ok && id.Obj == nil && (id.Name == "new" || id.Name == "make")
+	}
+	return false
+}
+
+func forLoopVars(x *ast.ForStmt) []ast.Expr {
+	init, ok := x.Init.(*ast.AssignStmt)
+	if ok && init.Tok == token.DEFINE {
+		return init.Lhs
+	}
+	return nil
+}
+
+func rangeLoopVars(x *ast.RangeStmt) []ast.Expr {
+	if x.Tok == token.DEFINE {
+		return []ast.Expr{x.Key, x.Value}
+	}
+	return nil
+}
+
+func identExprs(ids []*ast.Ident) []ast.Expr {
+	var res []ast.Expr
+	for _, id := range ids {
+		res = append(res, id)
+	}
+	return res
+}
+
+// getMakeChanInfo returns information on the channel created by x if
+// x is a call to make for a channel type, or nil otherwise.
+func getMakeChanInfo(x *ast.CallExpr, v *Visitor) *ChanInfo {
+	id, ok := x.Fun.(*ast.Ident)
+	if ok && id.Name == "make" && len(x.Args) > 0 {
+		t, ok := x.Args[0].(*ast.ChanType)
+		if ok {
+			var buf bytes.Buffer
+			printer.Fprint(&buf, v.fset, t.Value)
+			info := &ChanInfo{elemType: buf.String(), dir: t.Dir, buffer: Unbuffered}
+			if len(x.Args) == 2 {
+				bsize, ok := x.Args[1].(*ast.BasicLit)
+				if ok && bsize.Kind == token.INT {
+					if bsize.Value != "0" {
+						info.buffer = LiteralBuffer
+						info.capacity = bsize.Value
+					}
+				} else {
+					var cbuf bytes.Buffer
+					printer.Fprint(&cbuf, v.fset, x.Args[1])
+					info.buffer = ComputedBuffer
+					info.capacity = cbuf.String()
+				}
+			}
+			return info
+		}
+	}
+	return nil
+}
+
+// getChanTypeInfo returns information on the channel type t. Since only
+// the type is known, the buffering of the channel is unknown.
+func getChanTypeInfo(t *ast.ChanType, v *Visitor) *ChanInfo {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, t.Value)
+	return &ChanInfo{elemType: buf.String(), dir: t.Dir, buffer: UnknownBuffer}
+}
+
+func matchMakeCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	info := getMakeChanInfo(x, v)
+	if info != nil {
+		fmt.Printf("Found a channel %s at %s\n", info.String(), v.fset.Position(n.Pos()))
+		v.state.addChanMake(info.buffer)
+	}
+}
+
+// getCollectionElem returns the kind of collection t is, and the type of
+// its elements with pointers unwrapped. The kind of a nested collection
+// such as [][]sync.Mutex is that of the outermost one.
+func getCollectionElem(t ast.Expr) (CollectionKind, ast.Expr) {
+	kind := NoCollection
+	for {
+		var elem ast.Expr
+		switch x := t.(type) {
+		case *ast.ArrayType:
+			elem = x.Elt
+			if kind == NoCollection && x.Len == nil {
+				kind = SliceCollection
+			} else if kind == NoCollection {
+				kind = ArrayCollection
+			}
+		case *ast.MapType:
+			elem = x.Value
+			if kind == NoCollection {
+				kind = MapCollection
+			}
+		default:
+			return kind, getStarElem(t)
+		}
+		t = elem
+	}
+}
+
+// getInitCollectionType returns the type of an initializer of the form
+// make(T, ...) or T{}, or nil otherwise.
+func getInitCollectionType(e ast.Expr) ast.Expr {
+	switch x := e.(type) {
+	case *ast.CompositeLit:
+		return x.Type
+	case *ast.CallExpr:
+		id, ok := x.Fun.(*ast.Ident)
+		if ok && id.Name == "make" && len(x.Args) > 0 {
+			return x.Args[0]
+		}
+	}
+	return nil
+}
+
+// addCollectionDef declares name as a collection of type t if its
+// elements are primitives or channels. A collection of a named type is
+// recorded like a variable of that type, so that shards[i].Lock()
+// resolves when the type embeds a primitive.
+func addCollectionDef(name string, t ast.Expr, v *Visitor) {
+	if t == nil || name == "_" {
+		return
+	}
+	kind, elem := getCollectionElem(t)
+	if kind == NoCollection {
+		return
+	}
+	if ct, ok := elem.(*ast.ChanType); ok {
+		info := getChanTypeInfo(ct, v)
+		fmt.Printf("Found declaration of %s of channels %s: %s\n", kind.String(), name, info.String())
+		d := createChanDecl(name, info)
+		d.collection = kind
+		v.addDef(d)
+		v.state.addChanDecl(info.dir)
+		v.state.addCollectionDecl(kind)
+	} else if typeof, ok := getSyncDeclType(getGenericBase(elem), v); ok {
+		fmt.Printf("Found declaration of %s of %s %s\n", kind.String(), typeof.String(), name)
+		d := createDecl(name, typeof)
+		d.collection = kind
+		v.addDef(d)
+		addDeclCount(typeof, v)
+		v.state.addCollectionDecl(kind)
+	} else if typeName := getNamedType(elem); typeName != "" {
+		v.addTypedVar(name, typeName)
+	}
+}
+
+func matchCollectionDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				if spec.Type != nil {
+					addCollectionDef(spec.Names[j].Name, spec.Type, v)
+				} else if len(spec.Names) == len(spec.Values) {
+					addCollectionDef(spec.Names[j].Name, getInitCollectionType(spec.Values[j]), v)
+				}
+			}
+		}
+	}
+}
+
+func matchCollectionParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		addCollectionDef(x.Names[i].Name, x.Type, v)
+	}
+}
+
+func matchCollectionAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	if x.Tok != token.DEFINE || len(x.Lhs) != len(x.Rhs) {
+		return
+	}
+	for i := 0; i < len(x.Rhs); i++ {
+		id, ok := x.Lhs[i].(*ast.Ident)
+		if ok {
+			addCollectionDef(id.Name, getInitCollectionType(x.Rhs[i]), v)
+		}
+	}
+}
+
+// matchCollectionRangeDecl declares the value variable of a range loop
+// over a collection of primitives, as in for _, mu := range locks, with
+// the type of its elements. It is not counted as a declaration.
+func matchCollectionRangeDecl(x *ast.RangeStmt, v *Visitor, n ast.Node) {
+	id, ok := x.Value.(*ast.Ident)
+	if !ok || x.Tok != token.DEFINE || id.Name == "_" {
+		return
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, x.X)
+	vs, ok := v.state.lookup(buf.String())
+	if ok && len(vs) == 1 && vs[0].collection != NoCollection {
+		fmt.Printf("Found %s ranging over %s %s\n", id.Name, vs[0].collection.String(), vs[0].name)
+		v.addDef(Declaration{name: id.Name, typeof: vs[0].typeof, chanInfo: vs[0].chanInfo})
+	}
+}
+
+// matchIndexedCall counts method calls on a primitive reached through an
+// index expression, such as locks[i].Lock() or shards[h%n].mu.Lock().
+func matchIndexedCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	sel, ok := x.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, sel.X)
+	target := buf.String()
+	if !strings.Contains(target, "[") {
+		return
+	}
+	vs, ok := v.state.lookup(target)
+	if ok && len(vs) == 1 && vs[0].typeof != Unknown {
+		fmt.Printf("Found call of %s through index expression %s\n", sel.Sel.Name, target)
+		v.state.addIndexedCalls()
+	}
+}
+
+func matchChanDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				var info *ChanInfo
+				if j < len(spec.Values) {
+					call, ok := spec.Values[j].(*ast.CallExpr)
+					if ok {
+						info = getMakeChanInfo(call, v)
+					}
+				}
+				t, ok := spec.Type.(*ast.ChanType)
+				if ok {
+					typeInfo := getChanTypeInfo(t, v)
+					if info != nil {
+						// The declared type gives the direction, make gives the buffering
+						typeInfo.buffer = info.buffer
+						typeInfo.capacity = info.capacity
+					}
+					info = typeInfo
+				}
+				if info != nil {
+					fmt.Printf("Found declaration of channel %s: %s\n", id.Name, info.String())
+					v.addDef(createChanDecl(id.Name, info))
+					v.state.addChanDecl(info.dir)
+				}
+			}
+		}
+	}
+}
+
+func matchChanParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		t, ok := x.Type.(*ast.ChanType)
+		if ok {
+			info := getChanTypeInfo(t, v)
+			fmt.Printf("Found declaration of channel field %s: %s\n", fieldName.Name, info.String())
+			v.addDef(createChanDecl(fieldName.Name, info))
+			v.state.addChanDecl(info.dir)
+		}
+	}
+}
+
+// matchChanAssignDecl matches channels declared with make, as in
+// c := make(chan int), and records the make assigned to a field or an
+// existing variable, as in s.sigs = make(chan os.Signal), so that the
+// buffer of a channel declared without one can be found.
+func matchChanAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	if (x.Tok != token.DEFINE && x.Tok != token.ASSIGN) || len(x.Lhs) != len(x.Rhs) {
+		return
+	}
+	for i := 0; i < len(x.Rhs); i++ {
+		call, ok := x.Rhs[i].(*ast.CallExpr)
+		if ok {
+			info := getMakeChanInfo(call, v)
+			if info != nil && x.Tok == token.ASSIGN {
+				var buf bytes.Buffer
+				printer.Fprint(&buf, v.fset, x.Lhs[i])
+				fmt.Printf("Found assignment of channel %s: %s\n", buf.String(), info.String())
+				a := ChanAssign{target: buf.String(), pos: x.Pos(), info: info}
+				v.state.chanAssigns = append(v.state.chanAssigns, a)
+			} else if info != nil {
+				id, ok := x.Lhs[i].(*ast.Ident)
+				if ok && id.Name != "_" {
+					fmt.Printf("Found declaration of channel %s: %s\n", id.Name, info.String())
+					v.addDef(createChanDecl(id.Name, info))
+					v.state.addChanDecl(info.dir)
+				}
+			}
+		}
+	}
+}
+
+func matchSendStmt(x *ast.SendStmt, v *Visitor, n ast.Node) {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, x.Chan)
+	fmt.Printf("Found a send to channel %s at %s\n", buf.String(), v.fset.Position(n.Pos()))
+	v.state.addSend(buf.String())
+}
+
+func matchUnaryExpr(x *ast.UnaryExpr, v *Visitor, n ast.Node) {
+	if x.Op == token.ARROW {
+		var buf bytes.Buffer
+		call, ok := x.X.(*ast.CallExpr)
+		if ok && len(call.Args) == 0 {
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if ok && sel.Sel.Name == "Done" {
+				printer.Fprint(&buf, v.fset, sel.X)
+				fmt.Printf("Found a receive from Done channel of %s at %s\n", buf.String(), v.fset.Position(n.Pos()))
+				v.state.addDoneRecv(buf.String())
+				return
+			}
+		}
+		printer.Fprint(&buf, v.fset, x.X)
+		if isTimerRecv(x, v) {
+			fmt.Printf("Found a receive from timer channel %s at %s\n", buf.String(), v.fset.Position(n.Pos()))
+			v.state.addChanRecv()
+			return
+		}
+		fmt.Printf("Found a receive from channel %s at %s\n", buf.String(), v.fset.Position(n.Pos()))
+		v.state.addRecv(buf.String())
+	}
+}
+
+// getRecvOkTarget returns the channel expression of a comma-ok receive,
+// such as v, ok := <-ch, or nil if lhs and rhs do not have that form.
+func getRecvOkTarget(lhs int, rhs []ast.Expr) ast.Expr {
+	if lhs == 2 && len(rhs) == 1 {
+		recv, ok := rhs[0].(*ast.UnaryExpr)
+		if ok && recv.Op == token.ARROW {
+			return recv.X
+		}
+	}
+	return nil
+}
+
+func matchRecvOkAssign(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	target := getRecvOkTarget(len(x.Lhs), x.Rhs)
+	if target != nil {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, target)
+		fmt.Printf("Found a comma-ok receive from channel %s at %s\n", buf.String(), v.fset.Position(n.Pos()))
+		v.state.addRecvOk(buf.String())
+	}
+}
+
+func matchRecvOkDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			target := getRecvOkTarget(len(spec.Names), spec.Values)
+			if target != nil {
+				var buf bytes.Buffer
+				printer.Fprint(&buf, v.fset, target)
+				fmt.Printf("Found a comma-ok receive from channel %s at %s\n", buf.String(), v.fset.Position(n.Pos()))
+				v.state.addRecvOk(buf.String())
+			}
+		}
+	}
+}
+
+func matchRangeStmt(x *ast.RangeStmt, v *Visitor, n ast.Node) {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, x.X)
+	v.state.addRange(buf.String())
+}
+
+func matchCloseCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	id, ok := x.Fun.(*ast.Ident)
+	if ok && id.Name == "close" && len(x.Args) == 1 {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.Args[0])
+		fmt.Printf("Found call of close on channel %s\n", buf.String())
+		v.state.addClose(buf.String())
+	}
+}
+
+// getPackageMember returns the name of the member of the package with
+// import path path that e refers to, or "" if e does not refer to a
+// member of that package. Qualified references through the package
+// name or an import alias are recognized, as are bare references
+// through a dot import. Identifiers the parser resolved to a local
+// declaration, such as a variable named sync, never refer to a
+// package.
+func getPackageMember(e ast.Expr, v *Visitor, path string) string {
+	switch x := e.(type) {
+	case *ast.SelectorExpr:
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isPackage(id.Name, path) {
+			return x.Sel.Name
+		}
+	case *ast.Ident:
+		if x.Obj == nil && v.state.isDotImport(path) {
+			return x.Name
+		}
+	}
+	return ""
+}
+
+func matchImportSpec(x *ast.ImportSpec, v *Visitor, n ast.Node) {
+	path, err := strconv.Unquote(x.Path.Value)
+	if err != nil {
+		return
+	}
+	if x.Name != nil && x.Name.Name == "." {
+		v.state.addDotImport(path)
+	} else if x.Name != nil && x.Name.Name == "_" {
+		return
+	} else if x.Name != nil {
+		v.state.addImport(x.Name.Name, path)
+	} else {
+		v.state.addImport(filepath.Base(path), path)
+	}
+}
+
+// countLoopVarCaptures returns the number of distinct loop variables
+// referenced in the body of closure fn. Names are resolved through the
+// scopes of the file, so parameters, variables redeclared in the closure
+// and rebindings such as v := v before the go statement shadow the loop
+// variables, and field names in selectors are ignored.
+func countLoopVarCaptures(fn *ast.FuncLit, v *Visitor) int {
+	captured := map[string]bool{}
+	var inspect func(node ast.Node) bool
+	inspect = func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(x.X, inspect)
+			return false
+		case *ast.Ident:
+			if v.isLoopVar(x) {
+				captured[x.Name] = true
+			}
+		}
+		return true
+	}
+	ast.Inspect(fn.Body, inspect)
+	return len(captured)
+}
+
+// findLoopVar returns the first loop variable used in the expression e,
+// such as x in &x, x+1 or items[x], or nil if there is none. Field names
+// in selectors and in struct literals such as T{i: 0} are left out.
+func findLoopVar(e ast.Expr, v *Visitor) *ast.Ident {
+	var found *ast.Ident
+	var inspect func(node ast.Node) bool
+	inspect = func(node ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		switch x := node.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(x.X, inspect)
+			return false
+		case *ast.CompositeLit:
+			if _, ok := x.Type.(*ast.MapType); ok {
+				return true
+			}
+			for _, elt := range x.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if ok {
+					if _, isIdent := kv.Key.(*ast.Ident); isIdent {
+						elt = kv.Value
+					}
+				}
+				ast.Inspect(elt, inspect)
+			}
+			return false
+		case *ast.Ident:
+			if v.isLoopVar(x) {
+				found = x
+			}
+		}
+		return true
+	}
+	ast.Inspect(e, inspect)
+	return found
+}
+
+func matchGoStmt(x *ast.GoStmt, v *Visitor, n ast.Node) {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, x.Call.Fun)
+	v.state.addGoStmt()
+
+	switch fun := x.Call.Fun.(type) {
+	case *ast.FuncLit:
+		fmt.Printf("Found go statement launching a closure at %s\n", v.fset.Position(n.Pos()))
+		v.state.addGoClosure()
+		if v.loopDepth > 0 && countLoopVarCaptures(fun, v) > 0 {
+			fmt.Printf("Found closure capturing loop variables at %s\n", v.fset.Position(n.Pos()))
+			v.state.addGoLoopVarCapture()
+		}
+	case *ast.Ident:
+		fmt.Printf("Found go statement launching function %s\n", buf.String())
+		v.state.addGoNamedFunc()
+	case *ast.SelectorExpr:
+		id, ok := fun.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			fmt.Printf("Found go statement launching function %s\n", buf.String())
+			v.state.addGoNamedFunc()
+		} else {
+			fmt.Printf("Found go statement launching method %s\n", buf.String())
+			v.state.addGoMethod()
+		}
+	default:
+		fmt.Printf("Found go statement launching %s\n", buf.String())
+		v.state.addGoOther()
+	}
+
+	if v.testFunc == "Test" {
+		fmt.Printf("Found go statement inside a test at %s\n", v.fset.Position(n.Pos()))
+		v.state.addGoInTest()
+	} else if v.testFunc == "Benchmark" {
+		fmt.Printf("Found go statement inside a benchmark at %s\n", v.fset.Position(n.Pos()))
+		v.state.addGoInBenchmark()
+	}
+
+	if v.loopDepth > 0 {
+		fmt.Printf("Found go statement inside a loop at %s\n", v.fset.Position(n.Pos()))
+		v.state.addGoInLoop()
+
+		// The receiver of a method value is evaluated when the go
+		// statement runs, so it is treated like an argument
+		args := x.Call.Args
+		sel, ok := x.Call.Fun.(*ast.SelectorExpr)
+		if ok {
+			args = append([]ast.Expr{sel.X}, args...)
+		}
+		for _, arg := range args {
+			id := findLoopVar(arg, v)
+			if id != nil {
+				fmt.Printf("Found loop variable %s passed to go statement at %s\n", id.Name, v.fset.Position(n.Pos()))
+				v.state.addGoLoopVarArg()
+				break
+			}
+		}
+	}
+}
+
+// getCommRecv returns the receive expression of a select case, or nil
+// if the case is a send or the default case.
+func getCommRecv(comm ast.Stmt) *ast.UnaryExpr {
+	var e ast.Expr
+	switch c := comm.(type) {
+	case *ast.ExprStmt:
+		e = c.X
+	case *ast.AssignStmt:
+		if len(c.Rhs) == 1 {
+			e = c.Rhs[0]
+		}
+	}
+	recv, ok := e.(*ast.UnaryExpr)
+	if ok && recv.Op == token.ARROW {
+		return recv
+	}
+	return nil
+}
+
+// isTimerRecv checks if recv receives from a timer channel, either
+// directly from time.After or time.Tick, or from the C field of a
+// Timer or Ticker.
+func isTimerRecv(recv *ast.UnaryExpr, v *Visitor) bool {
+	switch x := recv.X.(type) {
+	case *ast.CallExpr:
+		name := getPackageMember(x.Fun, v, "time")
+		return name == "After" || name == "Tick"
+	case *ast.SelectorExpr:
+		if x.Sel.Name == "C" {
+			var buf bytes.Buffer
+			printer.Fprint(&buf, v.fset, x.X)
+			return v.state.isTimerTarget(buf.String())
+		}
+	}
+	return false
+}
+
+func matchSelectStmt(x *ast.SelectStmt, v *Visitor, n ast.Node) {
+	v.state.addSelectStmt()
+	pos := v.fset.Position(n.Pos())
+
+	cases := 0
+	for _, stmt := range x.Body.List {
+		clause, ok := stmt.(*ast.CommClause)
+		if !ok {
+			continue
+		}
+		v.state.addSelectCase()
+		if clause.Comm == nil {
+			fmt.Printf("Found default case in select at %s\n", pos)
+			v.state.addSelectDefault()
+			continue
+		}
+		cases++
+		_, ok = clause.Comm.(*ast.SendStmt)
+		if ok {
+			fmt.Printf("Found send case in select at %s\n", pos)
+			v.state.addSelectSendCase()
+		} else {
+			recv := getCommRecv(clause.Comm)
+			fmt.Printf("Found receive case in select at %s\n", pos)
+			v.state.addSelectRecvCase()
+			if recv != nil && isTimerRecv(recv, v) {
+				fmt.Printf("Found timer case in select at %s\n", pos)
+				v.state.addSelectTimerCase()
+			}
+		}
+	}
+
+	if len(x.Body.List) == 0 {
+		fmt.Printf("Found empty select blocking forever at %s\n", pos)
+		v.state.addSelectEmpty()
+	} else if cases == 1 && len(x.Body.List) == 1 {
+		fmt.Printf("Found single case select at %s\n", pos)
+		v.state.addSelectSingleCase()
+	}
+	if v.loopDepth > 0 {
+		fmt.Printf("Found select inside a loop at %s\n", pos)
+		v.state.addSelectInLoop()
+	}
+}
+
+func matchWaitGroupDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if getPackageMember(spec.Type, v, "sync") == "WaitGroup" {
+					fmt.Printf("Found declaration of waitgroup %s\n", id.Name)
+					v.addDef(createDecl(id.Name, WaitGroup))
+					v.state.addWaitGroupDecl()
+				}
+			}
+		}
+	}
+}
+
+func matchWaitGroupParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "sync") == "WaitGroup" {
+			fmt.Printf("Found declaration of WaitGroup field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, WaitGroup))
+			v.state.addWaitGroupDecl()
+		}
+	}
+}
+
+func matchMutexDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if getPackageMember(spec.Type, v, "sync") == "Mutex" {
+					fmt.Printf("Found declaration of mutex %s\n", id.Name)
+					v.addDef(createDecl(id.Name, Mutex))
+					v.state.addMutexDecl()
+				}
+			}
+		}
+	}
+}
+
+func matchMutexParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "sync") == "Mutex" {
+			fmt.Printf("Found declaration of Mutex field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, Mutex))
+			v.state.addMutexDecl()
+		}
+	}
+}
+
+func matchRWMutexDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if getPackageMember(spec.Type, v, "sync") == "RWMutex" {
+					fmt.Printf("Found declaration of rwmutex %s\n", id.Name)
+					v.addDef(createDecl(id.Name, RWMutex))
+					v.state.addRWMutexDecl()
+				}
+			}
+		}
+	}
+}
+
+func matchRWMutexParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "sync") == "RWMutex" {
+			fmt.Printf("Found declaration of RWMutex field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, RWMutex))
+			v.state.addRWMutexDecl()
+		}
+	}
+}
+
+func matchLockerDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	if len(x.Specs) > 0 {
+		spec, ok := x.Specs[0].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if getPackageMember(spec.Type, v, "sync") == "Locker" {
+					fmt.Printf("Found declaration of locker %s\n", id.Name)
+					v.addDef(createDecl(id.Name, Locker))
+					v.state.addLockerDecl()
+				}
+			}
+		}
+	}
+}
+
+func matchLockerParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "sync") == "Locker" {
+			fmt.Printf("Found declaration of Locker field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, Locker))
+			v.state.addLockerDecl()
+		}
+	}
+}
+
+func matchOnceDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if getPackageMember(spec.Type, v, "sync") == "Once" {
+					fmt.Printf("Found declaration of once %s\n", id.Name)
+					v.addDef(createDecl(id.Name, Once))
+					v.state.addOnceDecl()
+				}
+			}
+		}
+	}
+}
+
+func matchCondDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if getPackageMember(spec.Type, v, "sync") == "Cond" {
+					fmt.Printf("Found declaration of cond %s\n", id.Name)
+					v.addDef(createDecl(id.Name, Cond))
+					v.state.addCondDecl()
+				}
+			}
+		}
+	}
+}
+
+func matchCondAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Rhs); i++ {
+		if isNewCondCall(x.Rhs[i], v) {
+			id, ok := x.Lhs[0].(*ast.Ident)
+			if ok {
+				fmt.Printf("Found declaration of cond %s\n", id.Name)
+				v.addDef(createDecl(id.Name, Cond))
+				v.state.addCondDecl()
+			}
+		}
+	}
+}
+
+func matchOnceParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "sync") == "Once" {
+			fmt.Printf("Found declaration of Once field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, Once))
+			v.state.addOnceDecl()
+		}
+	}
+}
+
+func matchSyncMapDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if getPackageMember(spec.Type, v, "sync") == "Map" {
+					fmt.Printf("Found declaration of sync.Map %s\n", id.Name)
+					v.addDef(createDecl(id.Name, SyncMap))
+					v.state.addSyncMapDecl()
+				}
+			}
+		}
+	}
+}
+
+func matchSyncMapParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "sync") == "Map" {
+			fmt.Printf("Found declaration of Map field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, SyncMap))
+			v.state.addSyncMapDecl()
+		}
+	}
+}
+
+func matchPoolDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if getPackageMember(spec.Type, v, "sync") == "Pool" {
+					fmt.Printf("Found declaration of pool %s\n", id.Name)
+					v.addDef(createDecl(id.Name, Pool))
+					v.state.addPoolDecl()
+				}
+			}
+		}
+	}
+}
+
+func matchPoolParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "sync") == "Pool" {
+			fmt.Printf("Found declaration of Pool field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, Pool))
+			v.state.addPoolDecl()
+		}
+	}
+}
+
+func isXSyncDeclType(typeof DeclType) bool {
+	return typeof == ErrGroup || typeof == Semaphore || typeof == SingleFlight
+}
+
+// matchXSyncDecl matches declarations of the golang.org/x/sync types
+// errgroup.Group, semaphore.Weighted and singleflight.Group, as well as
+// var declarations initialized from semaphore.NewWeighted.
+func matchXSyncDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				typeof, ok := getSyncDeclType(getStarElem(spec.Type), v)
+				if ok && isXSyncDeclType(typeof) {
+					fmt.Printf("Found declaration of %s %s\n", typeof.String(), id.Name)
+					v.addDef(createDecl(id.Name, typeof))
+					addDeclCount(typeof, v)
+				} else if spec.Type == nil && j == 0 && len(spec.Values) == 1 {
+					call, ok := spec.Values[0].(*ast.CallExpr)
+					if ok && getPackageMember(call.Fun, v, "golang.org/x/sync/semaphore") == "NewWeighted" {
+						fmt.Printf("Found declaration of Semaphore %s\n", id.Name)
+						v.addDef(createDecl(id.Name, Semaphore))
+						v.state.addSemaphoreDecl()
+					}
+				}
+			}
+		}
+	}
+}
+
+func matchXSyncParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		typeof, ok := getSyncDeclType(getFieldType(x), v)
+		if ok && isXSyncDeclType(typeof) {
+			fmt.Printf("Found declaration of %s field %s\n", typeof.String(), fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, typeof))
+			addDeclCount(typeof, v)
+		}
+	}
+}
+
+// matchXSyncAssignDecl matches g, ctx := errgroup.WithContext(ctx),
+// which declares both a group and a context, and
+// sem := semaphore.NewWeighted(n).
+func matchXSyncAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	if x.Tok != token.DEFINE || len(x.Rhs) != 1 {
+		return
+	}
+	call, ok := x.Rhs[0].(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	if getPackageMember(call.Fun, v, "golang.org/x/sync/errgroup") == "WithContext" && len(x.Lhs) == 2 {
+		id, ok := x.Lhs[0].(*ast.Ident)
+		if ok && id.Name != "_" {
+			fmt.Printf("Found declaration of ErrGroup %s\n", id.Name)
+			v.addDef(createDecl(id.Name, ErrGroup))
+			v.state.addErrGroupDecl()
+		}
+		id, ok = x.Lhs[1].(*ast.Ident)
+		if ok && id.Name != "_" {
+			fmt.Printf("Found declaration of context %s\n", id.Name)
+			v.addDef(createDecl(id.Name, Context))
+			v.state.addContextDecl()
+		}
+	} else if getPackageMember(call.Fun, v, "golang.org/x/sync/semaphore") == "NewWeighted" {
+		id, ok := x.Lhs[0].(*ast.Ident)
+		if ok && id.Name != "_" {
+			fmt.Printf("Found declaration of Semaphore %s\n", id.Name)
+			v.addDef(createDecl(id.Name, Semaphore))
+			v.state.addSemaphoreDecl()
+		}
+	}
+}
+
+func matchErrGroupWithContext(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	if getPackageMember(x.Fun, v, "golang.org/x/sync/errgroup") == "WithContext" {
+		fmt.Print("Found call of errgroup.WithContext\n")
+		v.state.addErrGroupWithCtx()
+	}
+}
+
+// getTimeConstructorType returns the declaration type of the value
+// returned by call if call is time.NewTimer, time.AfterFunc or
+// time.NewTicker.
+func getTimeConstructorType(call *ast.CallExpr, v *Visitor) (DeclType, bool) {
+	switch getPackageMember(call.Fun, v, "time") {
+	case "NewTimer", "AfterFunc":
+		return Timer, true
+	case "NewTicker":
+		return Ticker, true
+	}
+	return Unknown, false
+}
+
+func isTimeDeclType(typeof DeclType) bool {
+	return typeof == Timer || typeof == Ticker
+}
+
+func matchTimeDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				typeof, ok := getSyncDeclType(getStarElem(spec.Type), v)
+				if ok && isTimeDeclType(typeof) {
+					fmt.Printf("Found declaration of %s %s\n", typeof.String(), id.Name)
+					v.addDef(createDecl(id.Name, typeof))
+					addDeclCount(typeof, v)
+				} else if spec.Type == nil && len(spec.Names) == len(spec.Values) {
+					call, ok := spec.Values[j].(*ast.CallExpr)
+					if ok {
+						typeof, ok := getTimeConstructorType(call, v)
+						if ok {
+							fmt.Printf("Found declaration of %s %s\n", typeof.String(), id.Name)
+							v.addDef(createDecl(id.Name, typeof))
+							addDeclCount(typeof, v)
+						}
+					}
+				}
+				if j < len(spec.Values) {
+					call, ok := spec.Values[j].(*ast.CallExpr)
+					if ok && getPackageMember(call.Fun, v, "time") == "NewTicker" {
+						v.state.addTicker(id.Name, spec.Pos())
+					}
+				}
+			}
+		}
+	}
+}
+
+func matchTimeParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		typeof, ok := getSyncDeclType(getFieldType(x), v)
+		if ok && isTimeDeclType(typeof) {
+			fmt.Printf("Found declaration of %s field %s\n", typeof.String(), fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, typeof))
+			addDeclCount(typeof, v)
+		}
+	}
+}
+
+// matchTimeAssignDecl matches timers and tickers declared with :=, and
+// records the target every NewTicker result is assigned to, including
+// assignments to existing variables and fields.
+func matchTimeAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	if len(x.Lhs) != len(x.Rhs) {
+		return
+	}
+	for i := 0; i < len(x.Rhs); i++ {
+		call, ok := x.Rhs[i].(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		typeof, ok := getTimeConstructorType(call, v)
+		if !ok {
+			continue
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.Lhs[i])
+		name := splitTarget(buf.String())
+		if name == "_" {
+			continue
+		}
+		if x.Tok == token.DEFINE {
+			fmt.Printf("Found declaration of %s %s\n", typeof.String(), name)
+			v.addDef(createDecl(name, typeof))
+			addDeclCount(typeof, v)
+		}
+		if typeof == Ticker {
+			v.state.addTicker(buf.String(), x.Pos())
+		}
+	}
+}
+
+// matchDiscardedTicker warns about a ticker whose result is discarded, as
+// in a statement time.NewTicker(d), since it can never be stopped. A
+// ticker that is returned or passed to a function is owned by the
+// receiver, and is not reported.
+func matchDiscardedTicker(x *ast.ExprStmt, v *Visitor, n ast.Node) {
+	call, ok := x.X.(*ast.CallExpr)
+	if ok && getPackageMember(call.Fun, v, "time") == "NewTicker" {
+		fmt.Printf("Warning: ticker created by NewTicker is discarded and cannot be stopped at %s\n", v.fset.Position(n.Pos()))
+		v.state.addTickerNotStopped()
+	}
+}
+
+func matchTimeCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	switch getPackageMember(x.Fun, v, "time") {
+	case "After":
+		fmt.Print("Found call of time.After\n")
+		v.state.addTimeAfter()
+		if v.loopDepth > 0 {
+			fmt.Printf("Warning: time.After inside a loop creates a new timer on every iteration at %s\n", v.fset.Position(n.Pos()))
+			v.state.addTimeAfterInLoop()
+		}
+	case "Tick":
+		fmt.Print("Found call of time.Tick\n")
+		v.state.addTimeTick()
+	case "NewTimer":
+		fmt.Print("Found call of time.NewTimer\n")
+		v.state.addTimeNewTimer()
+	case "NewTicker":
+		fmt.Print("Found call of time.NewTicker\n")
+		v.state.addTimeNewTicker()
+	case "AfterFunc":
+		fmt.Print("Found call of time.AfterFunc\n")
+		v.state.addTimeAfterFunc()
+	}
+}
+
+// getNotifyChanInfo resolves the channel passed to signal.Notify, either
+// a call to make or a channel declared in the file, and returns nil if
+// it cannot be resolved.
+func getNotifyChanInfo(e ast.Expr, v *Visitor) *ChanInfo {
+	call, ok := e.(*ast.CallExpr)
+	if ok {
+		return getMakeChanInfo(call, v)
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, e)
+	vs, ok := v.state.lookup(buf.String())
+	if !ok || len(vs) != 1 || vs[0].typeof != Chan {
+		return nil
+	}
+	if vs[0].chanInfo != nil && vs[0].chanInfo.buffer != UnknownBuffer {
+		return vs[0].chanInfo
+	}
+	return v.state.assignedChanInfo(vs[0])
+}
+
+// assignedChanInfo returns the channel assigned by make to the channel
+// declared by d, as in s.sigs = make(chan os.Signal), or nil if there is
+// none or the assignments disagree on the buffer.
+func (s *AnalysisState) assignedChanInfo(d Declaration) *ChanInfo {
+	pos := s.pos
+	defer func() { s.pos = pos }()
+	var res *ChanInfo
+	for _, a := range s.chanAssigns {
+		s.pos = a.pos
+		vs, ok := s.lookupDecls(a.target)
+		if !ok || len(vs) != 1 || vs[0] != d {
+			continue
+		}
+		if res != nil && res.buffer != a.info.buffer {
+			fmt.Printf("Conflicting buffers assigned to channel %s\n", d.name)
+			return nil
+		}
+		res = a.info
+	}
+	return res
+}
+
+func matchSignalCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	switch getPackageMember(x.Fun, v, "os/signal") {
+	case "Notify":
+		fmt.Print("Found call of signal.Notify\n")
+		v.state.addSignalNotify()
+		if len(x.Args) == 0 {
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.Args[0])
+		info := getNotifyChanInfo(x.Args[0], v)
+		if info == nil || info.buffer == UnknownBuffer {
+			fmt.Printf("Could not resolve the buffer of channel %s passed to signal.Notify\n", buf.String())
+		} else if info.buffer == Unbuffered {
+			fmt.Printf("Warning: signal.Notify with unbuffered channel %s may drop signals at %s\n", buf.String(), v.fset.Position(n.Pos()))
+			v.state.addSignalUnbuffered()
+		}
+	case "Stop":
+		fmt.Print("Found call of signal.Stop\n")
+		v.state.addSignalStop()
+	case "Ignore":
+		fmt.Print("Found call of signal.Ignore\n")
+		v.state.addSignalIgnore()
+	case "NotifyContext":
+		fmt.Print("Found call of signal.NotifyContext\n")
+		v.state.addSignalNotifyCtx()
+	}
+}
+
+func getTestingDeclType(t ast.Expr, v *Visitor) (DeclType, bool) {
+	switch getPackageMember(getStarElem(t), v, "testing") {
+	case "T":
+		return TestingT, true
+	case "B":
+		return TestingB, true
+	case "PB":
+		return TestingPB, true
+	}
+	return Unknown, false
+}
+
+// matchTestingParamDecl records parameters of type *testing.T, *testing.B
+// and *testing.PB, so that calls such as t.Parallel() can be resolved.
+// They are not counted as declarations.
+func matchTestingParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		typeof, ok := getTestingDeclType(x.Type, v)
+		if ok {
+			fmt.Printf("Found declaration of %s parameter %s\n", typeof.String(), fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, typeof))
+		}
+	}
+}
+
+// getTestFuncKind returns "Test" or "Benchmark" if x is a test or
+// benchmark function in a _test.go file, and "" otherwise.
+func getTestFuncKind(x *ast.FuncDecl, v *Visitor) string {
+	if !v.state.testFile || x.Recv != nil || len(x.Type.Params.List) != 1 {
+		return ""
+	}
+	typeof, _ := getTestingDeclType(x.Type.Params.List[0].Type, v)
+	if strings.HasPrefix(x.Name.Name, "Test") && typeof == TestingT {
+		return "Test"
+	} else if strings.HasPrefix(x.Name.Name, "Benchmark") && typeof == TestingB {
+		return "Benchmark"
+	}
+	return ""
+}
+
+func matchRuntimeCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	switch getPackageMember(x.Fun, v, "runtime") {
+	case "Gosched":
+		fmt.Print("Found call of runtime.Gosched\n")
+		v.state.addRtGosched()
+	case "GOMAXPROCS":
+		fmt.Print("Found call of runtime.GOMAXPROCS\n")
+		v.state.addRtGOMAXPROCS()
+	case "LockOSThread":
+		fmt.Print("Found call of runtime.LockOSThread\n")
+		v.state.addRtLockOSThread()
+	case "UnlockOSThread":
+		fmt.Print("Found call of runtime.UnlockOSThread\n")
+		v.state.addRtUnlockOSThread()
+	case "NumGoroutine":
+		fmt.Print("Found call of runtime.NumGoroutine\n")
+		v.state.addRtNumGoroutine()
+	case "Goexit":
+		fmt.Print("Found call of runtime.Goexit\n")
+		v.state.addRtGoexit()
+	}
+	if getPackageMember(x.Fun, v, "runtime/debug") == "SetMaxThreads" {
+		fmt.Print("Found call of debug.SetMaxThreads\n")
+		v.state.addDbgSetMaxThreads()
+	}
+}
+
+func matchOnceFuncCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	switch getPackageMember(x.Fun, v, "sync") {
+	case "OnceFunc":
+		fmt.Print("Found call of sync.OnceFunc\n")
+		v.state.addOnceFunc()
+	case "OnceValue":
+		fmt.Print("Found call of sync.OnceValue\n")
+		v.state.addOnceValue()
+	case "OnceValues":
+		fmt.Print("Found call of sync.OnceValues\n")
+		v.state.addOnceValues()
+	}
+}
+
+// matchLegacyOnceValue matches once.Do(func() { x = ... }), where the
+// closure assigns to a variable declared outside of it. This is the
+// idiom sync.OnceValue and sync.OnceValues replace.
+func matchLegacyOnceValue(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	sel, ok := x.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Do" || len(x.Args) != 1 {
+		return
+	}
+	fn, ok := x.Args[0].(*ast.FuncLit)
+	if !ok {
+		return
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, sel.X)
+	if !v.state.hasDeclType(buf.String(), Once) {
+		return
+	}
+	captured := false
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		assign, ok := node.(*ast.AssignStmt)
+		if ok && assign.Tok == token.ASSIGN {
+			for _, lhs := range assign.Lhs {
+				id, ok := lhs.(*ast.Ident)
+				if ok && id.Name != "_" && (id.Obj == nil || id.Obj.Pos() < fn.Pos() || id.Obj.Pos() >= fn.End()) {
+					captured = true
+				}
+			}
+		}
+		return !captured
+	})
+	if captured {
+		fmt.Printf("Found legacy Once idiom assigning a captured variable at %s\n", v.fset.Position(n.Pos()))
+		v.state.addLegacyOnceValue()
+	}
+}
+
+// isWaitGroupCall checks if stmt is a call of method on the WaitGroup
+// named target, such as wg.Done().
+func isWaitGroupCall(stmt ast.Stmt, method string, target string, v *Visitor) bool {
+	var call *ast.CallExpr
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		call, _ = s.X.(*ast.CallExpr)
+	case *ast.DeferStmt:
+		call = s.Call
+	}
+	if call == nil {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != method {
+		return false
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, sel.X)
+	return buf.String() == target
+}
+
+// matchLegacyWaitGroupGo matches wg.Add(1) immediately followed by
+// go func() { defer wg.Done(); ... }(), the idiom WaitGroup.Go replaces.
+func matchLegacyWaitGroupGo(list []ast.Stmt, v *Visitor) {
+	for i := 0; i+1 < len(list); i++ {
+		expr, ok := list[i].(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := expr.X.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			continue
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		lit, isLit := call.Args[0].(*ast.BasicLit)
+		if !ok || sel.Sel.Name != "Add" || !isLit || lit.Value != "1" {
+			continue
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, sel.X)
+		target := buf.String()
+		if !v.state.hasDeclType(target, WaitGroup) {
+			continue
+		}
+		goStmt, ok := list[i+1].(*ast.GoStmt)
+		if !ok {
+			continue
+		}
+		fn, ok := goStmt.Call.Fun.(*ast.FuncLit)
+		if ok && len(fn.Body.List) > 0 && isWaitGroupCall(fn.Body.List[0], "Done", target, v) {
+			fmt.Printf("Found legacy WaitGroup idiom at %s\n", v.fset.Position(goStmt.Pos()))
+			v.state.addLegacyWgGo()
+		}
+	}
+}
+
+// matchPoolLiteral counts the New field set in a sync.Pool composite
+// literal, such as sync.Pool{New: func() any { return new(bytes.Buffer) }}.
+func matchPoolLiteral(x *ast.CompositeLit, v *Visitor, n ast.Node) {
+	if getPackageMember(x.Type, v, "sync") == "Pool" {
+		for _, elt := range x.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if ok {
+				key, ok := kv.Key.(*ast.Ident)
+				if ok && key.Name == "New" {
+					fmt.Printf("Found New field in pool literal at %s\n", v.fset.Position(n.Pos()))
+					v.state.addPoolNew()
+				}
+			}
+		}
+	}
+}
+
+func matchPoolNewAssign(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Lhs); i++ {
+		sel, ok := x.Lhs[i].(*ast.SelectorExpr)
+		if ok && sel.Sel.Name == "New" {
+			var buf bytes.Buffer
+			printer.Fprint(&buf, v.fset, sel.X)
+			v.state.addNewAssign(buf.String())
+		}
+	}
+}
+
+// getSyncDeclType returns the declaration type for the sync or
+// sync/atomic type t, if t is one of the tracked primitives.
+func getSyncDeclType(t ast.Expr, v *Visitor) (DeclType, bool) {
+	atomicName := getAtomicTypeName(t, v)
+	if atomicName == "Value" {
+		return AtomicValue, true
+	} else if atomicName != "" {
+		return AtomicTyped, true
+	}
+	switch getPackageMember(t, v, "sync") {
+	case "WaitGroup":
+		return WaitGroup, true
+	case "Cond":
+		return Cond, true
+	case "Once":
+		return Once, true
+	case "Mutex":
+		return Mutex, true
+	case "RWMutex":
+		return RWMutex, true
+	case "Locker":
+		return Locker, true
+	case "Map":
+		return SyncMap, true
+	case "Pool":
+		return Pool, true
+	}
+	switch getPackageMember(t, v, "time") {
+	case "Timer":
+		return Timer, true
+	case "Ticker":
+		return Ticker, true
+	}
+	if getPackageMember(t, v, "golang.org/x/sync/errgroup") == "Group" {
+		return ErrGroup, true
+	} else if getPackageMember(t, v, "golang.org/x/sync/semaphore") == "Weighted" {
+		return Semaphore, true
+	} else if getPackageMember(t, v, "golang.org/x/sync/singleflight") == "Group" {
+		return SingleFlight, true
+	}
+	return Unknown, false
+}
+
+// getInitDeclType infers the declaration type of a primitive from an
+// initializer of the form T{}, &T{} or new(T).
+func getInitDeclType(e ast.Expr, v *Visitor) (DeclType, bool) {
+	switch x := e.(type) {
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			lit, ok := x.X.(*ast.CompositeLit)
+			if ok {
+				return getSyncDeclType(lit.Type, v)
+			}
+		}
+	case *ast.CompositeLit:
+		return getSyncDeclType(x.Type, v)
+	case *ast.CallExpr:
+		id, ok := x.Fun.(*ast.Ident)
+		if ok && id.Name == "new" && len(x.Args) == 1 {
+			return getSyncDeclType(x.Args[0], v)
+		}
+	}
+	return Unknown, false
+}
+
+func isNewCondCall(e ast.Expr, v *Visitor) bool {
+	call, ok := e.(*ast.CallExpr)
+	return ok && getPackageMember(call.Fun, v, "sync") == "NewCond"
+}
+
+// addDeclCount counts a declaration of a primitive of type typeof.
+func addDeclCount(typeof DeclType, v *Visitor) {
+	switch typeof {
+	case WaitGroup:
+		v.state.addWaitGroupDecl()
+	case Cond:
+		v.state.addCondDecl()
+	case Once:
+		v.state.addOnceDecl()
+	case Mutex:
+		v.state.addMutexDecl()
+	case RWMutex:
+		v.state.addRWMutexDecl()
+	case Locker:
+		v.state.addLockerDecl()
+	case AtomicValue:
+		v.state.addAtomicValueDecl()
+	case AtomicTyped:
+		v.state.addAtomicTypedDecl()
+	case SyncMap:
+		v.state.addSyncMapDecl()
+	case Pool:
+		v.state.addPoolDecl()
+	case ErrGroup:
+		v.state.addErrGroupDecl()
+	case Semaphore:
+		v.state.addSemaphoreDecl()
+	case SingleFlight:
+		v.state.addSingleFlightDecl()
+	case Timer:
+		v.state.addTimerDecl()
+	case Ticker:
+		v.state.addTickerDecl()
+	}
+}
+
+func addInferredDef(name string, typeof DeclType, v *Visitor) {
+	fmt.Printf("Found declaration of %s %s from its initializer\n", typeof.String(), name)
+	v.addDef(createDecl(name, typeof))
+	addDeclCount(typeof, v)
+}
+
+// matchInferredDecl matches var declarations without a type, such as
+// var once = sync.Once{}, where the type comes from the initializer.
+func matchInferredDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok && spec.Type == nil && len(spec.Names) == len(spec.Values) {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				if id.Name == "_" {
+					continue
+				}
+				typeof, ok := getInitDeclType(spec.Values[j], v)
+				if ok {
+					addInferredDef(id.Name, typeof, v)
+				} else if isNewCondCall(spec.Values[j], v) {
+					addInferredDef(id.Name, Cond, v)
+				}
+			}
+		}
+	}
+}
+
+// matchInferredAssignDecl matches short variable declarations such as
+// wg := sync.WaitGroup{} or mu := new(sync.Mutex). Declarations using
+// sync.NewCond are handled by matchCondAssignDecl.
+func matchInferredAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	if x.Tok != token.DEFINE || len(x.Lhs) != len(x.Rhs) {
+		return
+	}
+	for i := 0; i < len(x.Rhs); i++ {
+		id, ok := x.Lhs[i].(*ast.Ident)
+		if ok && id.Name != "_" {
+			typeof, ok := getInitDeclType(x.Rhs[i], v)
+			if ok {
+				addInferredDef(id.Name, typeof, v)
+			}
+		}
+	}
+}
+
+// matchNewCondLocker counts a locker created inline as the argument to
+// sync.NewCond, as in sync.NewCond(&sync.Mutex{}). The locker has no
+// name, so only the declaration count is updated.
+func matchNewCondLocker(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	if isNewCondCall(x, v) && len(x.Args) == 1 {
+		typeof, ok := getInitDeclType(x.Args[0], v)
+		if ok {
+			fmt.Printf("Found declaration of anonymous %s passed to NewCond\n", typeof.String())
+			addDeclCount(typeof, v)
+		}
+	}
+}
+
+// getCondLocker describes the argument of a call of sync.NewCond.
+func getCondLocker(e ast.Expr, v *Visitor) (CondLocker, bool) {
+	if typeof, ok := getInitDeclType(e, v); ok {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, e)
+		key := fmt.Sprintf("%s at %s", buf.String(), v.fset.Position(e.Pos()))
+		return CondLocker{typeof: typeof, key: key}, true
+	}
+	readSide := false
+	call, ok := e.(*ast.CallExpr)
+	if ok && len(call.Args) == 0 {
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if ok && sel.Sel.Name == "RLocker" {
+			e = sel.X
+			readSide = true
+		}
+	}
+	unary, ok := e.(*ast.UnaryExpr)
+	if ok && unary.Op == token.AND {
+		e = unary.X
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, e)
+	key := buf.String()
+	if readSide {
+		key += ".RLocker()"
+	}
+	return CondLocker{target: buf.String(), readSide: readSide, key: key}, true
+}
+
+// matchCondLocker records the locker passed to sync.NewCond for the Cond
+// the result is assigned to, whether in an assignment, a var
+// declaration or a composite literal such as
+// Button{Clicked: sync.NewCond(&sync.Mutex{})}.
+func matchCondLocker(lhs ast.Expr, rhs ast.Expr, field bool, v *Visitor) {
+	if !isNewCondCall(rhs, v) {
+		return
+	}
+	call := rhs.(*ast.CallExpr)
+	if len(call.Args) != 1 {
+		return
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, lhs)
+	cond := splitTarget(buf.String())
+	locker, ok := getCondLocker(call.Args[0], v)
+	if _, isIdent := lhs.(*ast.Ident); isIdent && !field {
+		locker.scope = v.scope
+	}
+	if ok && cond != "_" {
+		fmt.Printf("Found Cond %s with locker %s\n", cond, locker.key)
+		v.state.addCondLocker(cond, locker)
+	}
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		paren, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = paren.X
+	}
+}
+
+// getAlias describes the primitive e refers to when it is assigned or
+// passed to a name: &mu, s.mu, m.RLocker(), locks[i] or a method value
+// such as s.mu.Unlock. It returns false for other expressions.
+func getAlias(e ast.Expr, v *Visitor) (Alias, bool) {
+	e = unparen(e)
+	a := Alias{pos: e.Pos()}
+	plain := true
+	if call, ok := e.(*ast.CallExpr); ok {
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "RLocker" || len(call.Args) != 0 {
+			return Alias{}, false
+		}
+		e = sel.X
+		a.readSide = true
+		plain = false
+	} else if unary, ok := e.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		e = unary.X
+		plain = false
+	}
+	switch x := e.(type) {
+	case *ast.Ident:
+		if x.Name == "nil" || x.Name == "_" || x.Name == "true" || x.Name == "false" {
+			return Alias{}, false
+		}
+	case *ast.SelectorExpr:
+		id, ok := x.X.(*ast.Ident)
+		if ok && v.state.isImportName(id.Name) {
+			return Alias{}, false
+		}
+		if plain && aliasMethods[x.Sel.Name] {
+			a.method = x.Sel.Name
+			e = x.X
+		}
+	case *ast.IndexExpr:
+	default:
+		return Alias{}, false
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, e)
+	a.target = buf.String()
+	return a, true
+}
+
+// getParamNames returns the names of the parameters of t in order, with
+// "" for unnamed parameters, or nil if t is variadic.
+func getParamNames(t *ast.FuncType) []string {
+	var names []string
+	for _, field := range t.Params.List {
+		if _, ok := field.Type.(*ast.Ellipsis); ok {
+			return nil
+		}
+		if len(field.Names) == 0 {
+			names = append(names, "")
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// matchAliasAssign records the names assigned an alias of a primitive,
+// as in mu := &s.mu or unlock := s.mu.Unlock, and the function literals
+// assigned to names, whose parameters are bound at their calls.
+func matchAliasAssign(lhs []ast.Expr, rhs []ast.Expr, v *Visitor) {
+	if len(lhs) != len(rhs) {
+		return
+	}
+	for i := range lhs {
+		id, ok := lhs[i].(*ast.Ident)
+		if !ok || id.Name == "_" {
+			continue
+		}
+		lit, ok := unparen(rhs[i]).(*ast.FuncLit)
+		if ok {
+			params := getParamNames(lit.Type)
+			if params != nil {
+				fmt.Printf("Found function literal %s\n", id.Name)
+				v.state.addAliasFunc(id.Name, AliasFunc{params: params, scope: v.scope,
+					paramScope: Scope{pos: lit.Pos(), end: lit.End()}})
 			}
+			continue
+		}
+		a, ok := getAlias(rhs[i], v)
+		if !ok {
+			continue
+		}
+		a.scope = v.scope
+		if a.method != "" {
+			fmt.Printf("Found alias %s of method value %s.%s\n", id.Name, a.target, a.method)
+			v.state.methodValues[unparen(rhs[i]).(*ast.SelectorExpr)] = true
 		} else {
-			fmt.Printf("Multiple matches for target %s for call to Broadcast\n", target)
-			s.addUnknownBroadcast()
+			fmt.Printf("Found alias %s of %s\n", id.Name, a.target)
+		}
+		v.state.addAlias(id.Name, a)
+	}
+}
+
+// matchAliasFuncDecl records a function, whose parameters are bound at
+// its calls in the file. Methods are left out.
+func matchAliasFuncDecl(x *ast.FuncDecl, v *Visitor, n ast.Node) {
+	params := getParamNames(x.Type)
+	if x.Recv == nil && params != nil {
+		v.state.addAliasFunc(x.Name.Name, AliasFunc{params: params, paramScope: Scope{pos: x.Pos(), end: x.End()}})
+	}
+}
+
+// matchAliasCall records a call of a function by name with the aliases
+// passed as arguments, such as producer(&wg, m.RLocker()).
+func matchAliasCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	id, ok := x.Fun.(*ast.Ident)
+	if !ok || x.Ellipsis != token.NoPos {
+		return
+	}
+	call := AliasCall{fun: id.Name, pos: x.Pos()}
+	found := false
+	for _, arg := range x.Args {
+		a, ok := getAlias(arg, v)
+		if ok && a.method == "" {
+			found = true
+		} else {
+			a = Alias{}
+		}
+		call.args = append(call.args, a)
+	}
+	if found {
+		v.state.aliasCalls = append(v.state.aliasCalls, call)
+	}
+}
+
+// matchMethodCall records the selector of a method call, such as
+// mu.Lock(), before the selector itself is visited.
+func matchMethodCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	sel, ok := unparen(x.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	id, ok := sel.X.(*ast.Ident)
+	if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+		// A package function such as http.Get, not a method
+		return
+	}
+	v.state.methodCalls[sel] = true
+}
+
+// matchMethodValueCall counts a call of a method value assigned to a
+// name, as in defer unlock(), as a call of the method on the primitive.
+func matchMethodValueCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	id, ok := x.Fun.(*ast.Ident)
+	if !ok {
+		return
+	}
+	method := v.state.aliasMethod(id.Name)
+	if method == "" {
+		return
+	}
+	fmt.Printf("Found call of method value %s for %s\n", id.Name, method)
+	switch method {
+	case "Lock":
+		v.state.addLock(id.Name)
+	case "Unlock":
+		v.state.addUnlock(id.Name)
+	case "RLock":
+		v.state.addRLock(id.Name)
+	case "RUnlock":
+		v.state.addRUnlock(id.Name)
+	case "TryLock":
+		v.state.addTryLock(id.Name)
+	case "TryRLock":
+		v.state.addTryRLock(id.Name)
+	case "Wait":
+		v.state.addWait(id.Name)
+	case "Done":
+		v.state.addDone(id.Name)
+	case "Add":
+		v.state.addAdd(id.Name)
+	case "Signal":
+		v.state.addSignal(id.Name)
+	case "Broadcast":
+		v.state.addBroadcast(id.Name)
+	case "Do":
+		v.state.addDo(id.Name)
+	}
+}
+
+// getNamedType returns the name of the type t if t is a type name, a
+// qualified type name such as store.Cache, an instantiation of a generic
+// type or a pointer to one of them, or "" otherwise.
+func getNamedType(t ast.Expr) string {
+	star, ok := t.(*ast.StarExpr)
+	if ok {
+		t = star.X
+	}
+	switch x := getGenericBase(t).(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.SelectorExpr:
+		pkg, ok := x.X.(*ast.Ident)
+		if ok {
+			return pkg.Name + "." + x.Sel.Name
+		}
+	}
+	return ""
+}
+
+// getInitNamedType returns the name of the type of an initializer of
+// the form T{}, &T{} or new(T), or "" if T is not a plain type name.
+func getInitNamedType(e ast.Expr) string {
+	switch x := e.(type) {
+	case *ast.UnaryExpr:
+		if x.Op == token.AND {
+			lit, ok := x.X.(*ast.CompositeLit)
+			if ok {
+				return getNamedType(lit.Type)
+			}
+		}
+	case *ast.CompositeLit:
+		return getNamedType(x.Type)
+	case *ast.CallExpr:
+		id, ok := x.Fun.(*ast.Ident)
+		if ok && id.Name == "new" && len(x.Args) == 1 {
+			return getNamedType(x.Args[0])
+		}
+	}
+	return ""
+}
+
+// matchGenericDecl records the primitives declared in the fields of a
+// generic struct type, such as the mu field of
+// type SafeMap[K comparable, V any] struct { mu sync.RWMutex }. The
+// fields themselves are counted once like those of any other type.
+func matchGenericDecl(x *ast.TypeSpec, v *Visitor, n ast.Node) {
+	st, ok := x.Type.(*ast.StructType)
+	if !ok || x.TypeParams == nil {
+		return
+	}
+	var prims []Declaration
+	for _, field := range st.Fields.List {
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		name := x.Name.Name
+		if ct, ok := field.Type.(*ast.ChanType); ok {
+			for i := 0; i < count; i++ {
+				prims = append(prims, createChanDecl(name, getChanTypeInfo(ct, v)))
+			}
+		} else if typeof, ok := getSyncDeclType(getFieldType(field), v); ok {
+			for i := 0; i < count; i++ {
+				prims = append(prims, createDecl(name, typeof))
+			}
 		}
-	} else {
-		fmt.Printf("No match for target %s for call to Broadcast\n", target)
-		s.addUnknownBroadcast()
+	}
+	if len(prims) > 0 {
+		fmt.Printf("Found generic type %s with %d primitives\n", x.Name.Name, len(prims))
+		v.state.generics[x.Name.Name] = prims
+		v.state.addGenericTypes()
 	}
 }
 
-func (s *AnalysisState) addDo(target string) {
-	target = splitTarget(target)
-	vs, ok := s.decls[target]
-	if ok {
-		if len(vs) == 1 {
-			if vs[0].typeof == Once {
-				fmt.Printf("Found use of Do for Once target %s\n", vs[0].name)
-				s.addOnceDo()
-			} else {
-				fmt.Printf("Unexpected match for target %s for call to Do\n", target)
-				s.addUnknownDo()
+// matchTypeParamDecl matches fields and parameters whose type is a type
+// parameter constrained by a primitive interface, such as l in
+// func With[L sync.Locker](l L), and declares them with that primitive.
+func matchTypeParamDecl(typeParams *ast.FieldList, fields *ast.FieldList, v *Visitor) {
+	constraints := map[string]DeclType{}
+	for _, field := range typeParams.List {
+		typeof, ok := getSyncDeclType(field.Type, v)
+		if ok {
+			for _, name := range field.Names {
+				constraints[name.Name] = typeof
+			}
+		}
+	}
+	if len(constraints) == 0 || fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		typeof, ok := constraints[getNamedType(field.Type)]
+		if ok {
+			for _, name := range field.Names {
+				fmt.Printf("Found declaration of %s %s through a type parameter\n", typeof.String(), name.Name)
+				v.addDef(createDecl(name.Name, typeof))
+				addDeclCount(typeof, v)
 			}
+		}
+	}
+}
+
+// matchGenericInst counts the distinct instantiations of generic types
+// that declare primitives. With the perInstantiation option, the
+// primitives of the generic type are counted again for each of them.
+func matchGenericInst(x ast.Expr, v *Visitor, n ast.Node) {
+	id, ok := getGenericBase(x).(*ast.Ident)
+	if !ok || id == x {
+		return
+	}
+	prims, ok := v.state.genericsOf(id.Name)
+	if !ok {
+		return
+	}
+	var buf bytes.Buffer
+	printer.Fprint(&buf, v.fset, x)
+	inst := strings.ReplaceAll(buf.String(), " ", "")
+	if v.state.instantiations[inst] {
+		return
+	}
+	v.state.instantiations[inst] = true
+	fmt.Printf("Found instantiation %s of generic type %s\n", inst, id.Name)
+	v.state.addGenericInsts()
+	if !v.state.options.perInstantiation {
+		return
+	}
+	for _, d := range prims {
+		fmt.Printf("Attributing %s of generic type %s to instantiation %s\n", d.typeof.String(), id.Name, inst)
+		if d.typeof == Chan {
+			v.state.addChanDecl(d.chanInfo.dir)
 		} else {
-			fmt.Printf("Multiple matches for target %s for call to Do\n", target)
-			s.addUnknownDo()
+			addDeclCount(d.typeof, v)
 		}
-	} else {
-		fmt.Printf("No match for target %s for call to Do\n", target)
-		s.addUnknownDo()
 	}
 }
 
-func main() {
-	var filePath string
-	flag.StringVar(&filePath, "filePath", "", "The file to be processed")
+// getEmbeddedFieldName returns the implicit name of an embedded field
+// of type t, which is the unqualified name of the type.
+func getEmbeddedFieldName(t ast.Expr) string {
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		return getEmbeddedFieldName(x.X)
+	case *ast.IndexListExpr:
+		return getEmbeddedFieldName(x.X)
+	case *ast.SelectorExpr:
+		return x.Sel.Name
+	case *ast.Ident:
+		return x.Name
+	}
+	return ""
+}
 
-	var dirPath string
-	flag.StringVar(&dirPath, "dirPath", "", "The directory to be processed")
+// matchEmbeddedDecl matches primitives embedded in a struct type, such
+// as type Cache struct { sync.Mutex }. The embedded field is declared
+// under its type name, so c.Mutex.Lock() resolves as well as c.Lock().
+func matchEmbeddedDecl(x *ast.TypeSpec, v *Visitor, n ast.Node) {
+	st, ok := x.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+	for _, field := range st.Fields.List {
+		if len(field.Names) != 0 {
+			continue
+		}
+		t := field.Type
+		star, ok := t.(*ast.StarExpr)
+		if ok {
+			t = star.X
+		}
+		typeof, ok := getSyncDeclType(t, v)
+		if ok {
+			fmt.Printf("Found embedded %s in type %s\n", typeof.String(), x.Name.Name)
+			v.addDef(createDecl(getEmbeddedFieldName(t), typeof))
+			addDeclCount(typeof, v)
+			v.state.addEmbed(x.Name.Name, typeof)
+		} else if name := getNamedType(t); name != "" {
+			v.state.addEmbeddedType(x.Name.Name, name)
+		}
+	}
+}
 
-	var outputFile string
-	flag.StringVar(&outputFile, "output", "", "The CSV file to be created")
+func matchTypedVarDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				typeName := getNamedType(spec.Type)
+				if spec.Type == nil && len(spec.Names) == len(spec.Values) {
+					typeName = getInitNamedType(spec.Values[j])
+				}
+				if typeName != "" && spec.Names[j].Name != "_" {
+					v.addTypedVar(spec.Names[j].Name, typeName)
+				}
+			}
+		}
+	}
+}
 
-	flag.Parse()
+func matchTypedVarParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	typeName := getNamedType(x.Type)
+	if typeName != "" {
+		for i := 0; i < len(x.Names); i++ {
+			v.addTypedVar(x.Names[i].Name, typeName)
+		}
+	}
+}
 
-	if outputFile != "" {
-		csvFile, err := os.Create(outputFile)
-		if err == nil {
-			writer := csv.NewWriter(csvFile)
-			if err := writer.Write(stateHeaders()); err != nil {
-				log.Fatalln("Error writing CSV file", err)
-			}
-			defer writer.Flush()
-			if dirPath != "" {
-				fmt.Printf("Processing all go files in directory %s\n", dirPath)
-				processDir(dirPath, writer)
-			} else if filePath != "" {
-				fmt.Printf("Processing file %s\n", filePath)
-				processFile(filePath, writer)
-			} else {
-				fmt.Print("No file or directory given\n")
+func matchTypedVarAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	if x.Tok != token.DEFINE || len(x.Lhs) != len(x.Rhs) {
+		return
+	}
+	for i := 0; i < len(x.Rhs); i++ {
+		id, ok := x.Lhs[i].(*ast.Ident)
+		if ok && id.Name != "_" {
+			typeName := getInitNamedType(x.Rhs[i])
+			if typeName != "" {
+				v.addTypedVar(id.Name, typeName)
 			}
-
-			csvFile.Close()
-		} else {
-			log.Fatalln("Error creating CSV file", err)
 		}
-	} else {
-		fmt.Print("No output file given\n")
 	}
+}
 
+// getStarElem returns the element type of t if t is a pointer type,
+// or t itself otherwise.
+func getStarElem(t ast.Expr) ast.Expr {
+	starType, ok := t.(*ast.StarExpr)
+	if ok {
+		return starType.X
+	}
+	return t
 }
 
-func processDir(dirPath string, writer *csv.Writer) {
-	var err = filepath.Walk(dirPath, func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			fmt.Printf("Encountered an error accessing path %q: %v\n", path, err)
-			return err
-		} else {
-			if filepath.Ext(path) == ".go" {
-				fmt.Printf("Processing file %s\n", path)
-				processFile(path, writer)
-				return nil
-			} else {
-				return nil
+// getGenericBase returns the generic type t instantiates, such as
+// atomic.Pointer for atomic.Pointer[Config], or t itself otherwise.
+func getGenericBase(t ast.Expr) ast.Expr {
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		return x.X
+	case *ast.IndexListExpr:
+		return x.X
+	}
+	return t
+}
+
+// getFieldType returns the type of field x with pointers and
+// instantiations of generic types unwrapped.
+func getFieldType(x *ast.Field) ast.Expr {
+	return getGenericBase(getStarElem(x.Type))
+}
+
+func matchContextDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Specs); i++ {
+		spec, ok := x.Specs[i].(*ast.ValueSpec)
+		if ok {
+			for j := 0; j < len(spec.Names); j++ {
+				id := spec.Names[j]
+				isContext := false
+				if getPackageMember(spec.Type, v, "context") == "Context" {
+					isContext = true
+				} else if spec.Type == nil && j == 0 && len(spec.Values) == 1 {
+					call, ok := spec.Values[0].(*ast.CallExpr)
+					isContext = ok && isContextConstructor(call, v)
+				}
+				if isContext {
+					fmt.Printf("Found declaration of context %s\n", id.Name)
+					v.addDef(createDecl(id.Name, Context))
+					v.state.addContextDecl()
+				}
 			}
 		}
-	})
+	}
+}
 
-	if err != nil {
-		fmt.Printf("Encountered an error walking the directory tree %q: %v", dirPath, err)
+func matchContextParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "context") == "Context" {
+			fmt.Printf("Found declaration of Context field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, Context))
+			v.state.addContextDecl()
+		}
 	}
 }
 
-func processFile(filePath string, writer *csv.Writer) {
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filePath, nil, 0)
-	if err != nil {
-		log.Printf("Could not process file %s", filePath)
-		log.Print(err)
-	} else {
-		fileState := &AnalysisState{decls: map[string][]Declaration{}}
-		declVisitor := &Visitor{fset: fset, mode: true, state: fileState}
-		ast.Walk(declVisitor, file)
-		usesVisitor := &Visitor{fset: fset, mode: false, state: fileState}
-		ast.Walk(usesVisitor, file)
-		if err := writer.Write(fileState.stateToSlice(filePath)); err != nil {
-			log.Fatalln("Error writing CSV file", err)
+// isContextConstructor checks if call is a function from the context
+// package whose first result is a Context.
+func isContextConstructor(call *ast.CallExpr, v *Visitor) bool {
+	switch getPackageMember(call.Fun, v, "context") {
+	case "Background", "TODO", "WithCancel", "WithTimeout", "WithDeadline",
+		"WithValue", "WithCancelCause", "WithTimeoutCause", "WithDeadlineCause",
+		"WithoutCancel":
+		return true
+	}
+	return false
+}
+
+func matchContextAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
+	if x.Tok != token.DEFINE || len(x.Rhs) != 1 {
+		return
+	}
+	call, ok := x.Rhs[0].(*ast.CallExpr)
+	if ok && isContextConstructor(call, v) {
+		id, ok := x.Lhs[0].(*ast.Ident)
+		if ok && id.Name != "_" {
+			fmt.Printf("Found declaration of context %s\n", id.Name)
+			v.addDef(createDecl(id.Name, Context))
+			v.state.addContextDecl()
 		}
-		writer.Flush()
 	}
 }
 
-type Visitor struct {
-	fset  *token.FileSet
-	mode  bool
-	state *AnalysisState
+func matchContextCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	switch getPackageMember(x.Fun, v, "context") {
+	case "WithCancel":
+		fmt.Print("Found call of context.WithCancel\n")
+		v.state.addCtxWithCancel()
+	case "WithTimeout":
+		fmt.Print("Found call of context.WithTimeout\n")
+		v.state.addCtxWithTimeout()
+	case "WithDeadline":
+		fmt.Print("Found call of context.WithDeadline\n")
+		v.state.addCtxWithDeadline()
+	case "WithValue":
+		fmt.Print("Found call of context.WithValue\n")
+		v.state.addCtxWithValue()
+	case "WithCancelCause":
+		fmt.Print("Found call of context.WithCancelCause\n")
+		v.state.addCtxWithCause()
+	}
 }
 
-func (v *Visitor) addDef(d Declaration) {
-	v.state.addDecl(d)
+// getAtomicTypeName returns the name of the sync/atomic type t refers
+// to, such as Value, Int64 or Pointer, or "" if t is not an atomic
+// type. Pointers and instantiations such as atomic.Pointer[T] are
+// unwrapped first.
+func getAtomicTypeName(t ast.Expr, v *Visitor) string {
+	name := getPackageMember(getGenericBase(getStarElem(t)), v, "sync/atomic")
+	switch name {
+	case "Value", "Bool", "Int32", "Int64", "Uint32", "Uint64", "Uintptr", "Pointer":
+		return name
+	}
+	return ""
 }
 
-// We should have a consistent return type with information on what
-// the match has found
-//func matchMakeCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
-//	id, ok := x.Fun.(*ast.Ident)
-//	if ok {
-//		if id.Name == "make" {
-//			if len(x.Args) == 1 {
-//				t, ok := x.Args[0].(*ast.ChanType)
-//				if ok {
-//					tname, ok := t.Value.(*ast.Ident)
-//					if ok {
-//						fmt.Printf("Found a channel of type %s at %s\n", tname.Name, v.fset.Position(n.Pos()))
-//					}
-//				}
-//			} else if len(x.Args) == 2 {
-//				t, ok := x.Args[0].(*ast.ChanType)
-//				if ok {
-//					tname, ok := t.Value.(*ast.Ident)
-//					if ok {
-//						bsize, ok := x.Args[1].(*ast.BasicLit)
-//						if ok {
-//							if bsize.Kind == token.INT {
-//								fmt.Printf("Found a channel of type %s with literal buffer size %s at %s\n", tname.Name, bsize.Value, v.fset.Position(n.Pos()))
-//							} else {
-//								fmt.Printf("Found a channel of type %s with buffer size %s at %s\n", tname.Name, bsize.Value, v.fset.Position(n.Pos()))
-//							}
-//						} else {
-//							fmt.Printf("Found a channel of type %s with computed buffer size %s at %s\n", tname.Name, x.Args[1], v.fset.Position(n.Pos()))
-//						}
-//					}
-//				}
-//			}
-//		}
-//	}
-//}
-
-//func matchSendStmt(x *ast.SendStmt, v *Visitor, n ast.Node) {
-//	id, ok := x.Chan.(*ast.Ident)
-//	if ok {
-//		fmt.Printf("Found a send to channel %s for value %s\n", id.Name, x.Value)
-//	}
-//}
-
-//func matchUnaryExpr(x *ast.UnaryExpr, v *Visitor, n ast.Node) {
-//	if x.Op == token.ARROW {
-//		id, ok := x.X.(*ast.Ident)
-//		if ok {
-//			fmt.Printf("Found a read of channel %s\n", id.Name)
-//		} else {
-//			fmt.Printf("Found a read of channel from expr %s\n", x.X)
-//		}
-//	}
-//}
+func addAtomicDef(name string, typeName string, v *Visitor) {
+	if typeName == "Value" {
+		fmt.Printf("Found declaration of atomic.Value %s\n", name)
+		v.addDef(createDecl(name, AtomicValue))
+		v.state.addAtomicValueDecl()
+	} else {
+		fmt.Printf("Found declaration of atomic.%s %s\n", typeName, name)
+		v.addDef(createDecl(name, AtomicTyped))
+		v.state.addAtomicTypedDecl()
+	}
+}
 
-func matchWaitGroupDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
+func matchAtomicDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
 	for i := 0; i < len(x.Specs); i++ {
 		spec, ok := x.Specs[i].(*ast.ValueSpec)
-		if ok {
-			for j := 0; j < len(spec.Names); j++ {
-				id := spec.Names[j]
-				t, ok := spec.Type.(*ast.SelectorExpr)
-				if ok {
-					tsel, ok := t.X.(*ast.Ident)
-					if ok {
-						if tsel.Name == "sync" && t.Sel.Name == "WaitGroup" {
-							fmt.Printf("Found declaration of waitgroup %s\n", id.Name)
-							v.addDef(createDecl(id.Name, WaitGroup))
-							v.state.addWaitGroupDecl()
-						}
-					}
+		if ok && spec.Type != nil {
+			typeName := getAtomicTypeName(spec.Type, v)
+			if typeName != "" {
+				for j := 0; j < len(spec.Names); j++ {
+					addAtomicDef(spec.Names[j].Name, typeName, v)
 				}
 			}
 		}
 	}
 }
 
-func matchWaitGroupParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Names); i++ {
-		fieldName := x.Names[i]
-
-		fieldType := getFieldType(x)
-
-		if fieldType != nil {
-			tsel, ok := fieldType.X.(*ast.Ident)
-			if ok {
-				if tsel.Name == "sync" && fieldType.Sel.Name == "WaitGroup" {
-					fmt.Printf("Found declaration of WaitGroup field %s\n", fieldName.Name)
-					v.addDef(createDecl(fieldName.Name, WaitGroup))
-					v.state.addWaitGroupDecl()
-				}
-			}
+func matchAtomicParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	typeName := getAtomicTypeName(x.Type, v)
+	if typeName != "" {
+		for i := 0; i < len(x.Names); i++ {
+			addAtomicDef(x.Names[i].Name, typeName, v)
+		}
+	}
+}
+
+// matchAtomicCall counts calls to the functions of sync/atomic, such
+// as atomic.AddInt64 or atomic.CompareAndSwapPointer.
+func matchAtomicCall(x *ast.CallExpr, v *Visitor, n ast.Node) {
+	name := getPackageMember(x.Fun, v, "sync/atomic")
+	if name != "" {
+		if strings.HasPrefix(name, "Add") {
+			fmt.Printf("Found call of atomic.%s\n", name)
+			v.state.addAtomicFuncAdd()
+		} else if strings.HasPrefix(name, "Load") {
+			fmt.Printf("Found call of atomic.%s\n", name)
+			v.state.addAtomicFuncLoad()
+		} else if strings.HasPrefix(name, "Store") {
+			fmt.Printf("Found call of atomic.%s\n", name)
+			v.state.addAtomicFuncStore()
+		} else if strings.HasPrefix(name, "Swap") {
+			fmt.Printf("Found call of atomic.%s\n", name)
+			v.state.addAtomicFuncSwap()
+		} else if strings.HasPrefix(name, "CompareAndSwap") {
+			fmt.Printf("Found call of atomic.%s\n", name)
+			v.state.addAtomicFuncCAS()
+		}
+	}
+}
+
+func matchCondParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
+	for i := 0; i < len(x.Names); i++ {
+		fieldName := x.Names[i]
+
+		fieldType := getFieldType(x)
+
+		if getPackageMember(fieldType, v, "sync") == "Cond" {
+			fmt.Printf("Found declaration of cond field %s\n", fieldName.Name)
+			v.addDef(createDecl(fieldName.Name, Cond))
+			v.state.addCondDecl()
+		}
+	}
+}
+
+func matchDone(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Done" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Done on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addDone(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addDoneFor(d.name, d)
+		}
+	}
+}
+
+func matchErr(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Err" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Err on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addErr(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addErrFor(d.name, d)
+		}
+	}
+}
+
+func matchLoad(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Load" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function such as http.Get, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Load on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addLoad(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addLoadFor(d.name, d)
+		}
+	}
+}
+
+func matchStore(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Store" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function such as http.Get, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Store on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addStore(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addStoreFor(d.name, d)
+		}
+	}
+}
+
+func matchSwap(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Swap" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Swap on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addSwap(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addSwapFor(d.name, d)
+		}
+	}
+}
+
+func matchCompareAndSwap(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "CompareAndSwap" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of CompareAndSwap on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addCompareAndSwap(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addCompareAndSwapFor(d.name, d)
+		}
+	}
+}
+
+func matchLoadOrStore(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "LoadOrStore" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of LoadOrStore on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addLoadOrStore(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addLoadOrStoreFor(d.name, d)
+		}
+	}
+}
+
+func matchLoadAndDelete(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "LoadAndDelete" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of LoadAndDelete on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addLoadAndDelete(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addLoadAndDeleteFor(d.name, d)
+		}
+	}
+}
+
+func matchDelete(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Delete" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function such as http.Get, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Delete on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addDelete(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addDeleteFor(d.name, d)
+		}
+	}
+}
+
+func matchRange(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Range" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function such as http.Get, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Range on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addRangeCall(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addRangeCallFor(d.name, d)
+		}
+	}
+}
+
+func matchGet(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Get" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function such as http.Get, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Get on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addGet(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addGetFor(d.name, d)
+		}
+	}
+}
+
+func matchPut(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Put" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function such as http.Get, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Put on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addPut(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addPutFor(d.name, d)
+		}
+	}
+}
+
+func matchGo(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Go" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Go on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addGo(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addGoFor(d.name, d)
 		}
 	}
 }
 
-func matchMutexDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Specs); i++ {
-		spec, ok := x.Specs[i].(*ast.ValueSpec)
-		if ok {
-			for j := 0; j < len(spec.Names); j++ {
-				id := spec.Names[j]
-				t, ok := spec.Type.(*ast.SelectorExpr)
-				if ok {
-					tsel, ok := t.X.(*ast.Ident)
-					if ok {
-						if tsel.Name == "sync" && t.Sel.Name == "Mutex" {
-							fmt.Printf("Found declaration of mutex %s\n", id.Name)
-							v.addDef(createDecl(id.Name, Mutex))
-							v.state.addMutexDecl()
-						}
-					}
-				}
-			}
+func matchTryGo(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "TryGo" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of TryGo on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addTryGo(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addTryGoFor(d.name, d)
 		}
 	}
 }
 
-func matchMutexParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Names); i++ {
-		fieldName := x.Names[i]
-
-		fieldType := getFieldType(x)
-
-		if fieldType != nil {
-			tsel, ok := fieldType.X.(*ast.Ident)
-			if ok {
-				if tsel.Name == "sync" && fieldType.Sel.Name == "Mutex" {
-					fmt.Printf("Found declaration of Mutex field %s\n", fieldName.Name)
-					v.addDef(createDecl(fieldName.Name, Mutex))
-					v.state.addMutexDecl()
-				}
-			}
+func matchSetLimit(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "SetLimit" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of SetLimit on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addSetLimit(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addSetLimitFor(d.name, d)
 		}
 	}
 }
 
-func matchRWMutexDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Specs); i++ {
-		spec, ok := x.Specs[i].(*ast.ValueSpec)
-		if ok {
-			for j := 0; j < len(spec.Names); j++ {
-				id := spec.Names[j]
-				t, ok := spec.Type.(*ast.SelectorExpr)
-				if ok {
-					tsel, ok := t.X.(*ast.Ident)
-					if ok {
-						if tsel.Name == "sync" && t.Sel.Name == "RWMutex" {
-							fmt.Printf("Found declaration of rwmutex %s\n", id.Name)
-							v.addDef(createDecl(id.Name, RWMutex))
-							v.state.addRWMutexDecl()
-						}
-					}
-				}
-			}
+func matchAcquire(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Acquire" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Acquire on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addAcquire(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addAcquireFor(d.name, d)
 		}
 	}
 }
 
-func matchRWMutexParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Names); i++ {
-		fieldName := x.Names[i]
-
-		fieldType := getFieldType(x)
-
-		if fieldType != nil {
-			tsel, ok := fieldType.X.(*ast.Ident)
-			if ok {
-				if tsel.Name == "sync" && fieldType.Sel.Name == "RWMutex" {
-					fmt.Printf("Found declaration of RWMutex field %s\n", fieldName.Name)
-					v.addDef(createDecl(fieldName.Name, RWMutex))
-					v.state.addRWMutexDecl()
-				}
-			}
+func matchTryAcquire(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "TryAcquire" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of TryAcquire on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addTryAcquire(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addTryAcquireFor(d.name, d)
 		}
 	}
 }
 
-func matchLockerDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
-	if len(x.Specs) > 0 {
-		spec, ok := x.Specs[0].(*ast.ValueSpec)
-		if ok {
-			for j := 0; j < len(spec.Names); j++ {
-				id := spec.Names[j]
-				t, ok := spec.Type.(*ast.SelectorExpr)
-				if ok {
-					tsel, ok := t.X.(*ast.Ident)
-					if ok {
-						if tsel.Name == "sync" && t.Sel.Name == "Locker" {
-							fmt.Printf("Found declaration of locker %s\n", id.Name)
-							v.addDef(createDecl(id.Name, Locker))
-							v.state.addLockerDecl()
-						}
-					}
-				}
-			}
+func matchRelease(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Release" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Release on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addRelease(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addReleaseFor(d.name, d)
 		}
 	}
 }
 
-func matchLockerParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Names); i++ {
-		fieldName := x.Names[i]
-
-		fieldType := getFieldType(x)
-
-		if fieldType != nil {
-			tsel, ok := fieldType.X.(*ast.Ident)
-			if ok {
-				if tsel.Name == "sync" && fieldType.Sel.Name == "Locker" {
-					fmt.Printf("Found declaration of Locker field %s\n", fieldName.Name)
-					v.addDef(createDecl(fieldName.Name, Locker))
-					v.state.addLockerDecl()
-				}
-			}
+func matchDoChan(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "DoChan" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of DoChan on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addDoChan(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addDoChanFor(d.name, d)
 		}
 	}
 }
 
-func matchOnceDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Specs); i++ {
-		spec, ok := x.Specs[i].(*ast.ValueSpec)
-		if ok {
-			for j := 0; j < len(spec.Names); j++ {
-				id := spec.Names[j]
-				t, ok := spec.Type.(*ast.SelectorExpr)
-				if ok {
-					tsel, ok := t.X.(*ast.Ident)
-					if ok {
-						if tsel.Name == "sync" && t.Sel.Name == "Once" {
-							fmt.Printf("Found declaration of once %s\n", id.Name)
-							v.addDef(createDecl(id.Name, Once))
-							v.state.addOnceDecl()
-						}
-					}
-				}
-			}
+func matchForget(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Forget" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Forget on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addForget(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addForgetFor(d.name, d)
 		}
 	}
 }
 
-func matchCondDecl(x *ast.GenDecl, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Specs); i++ {
-		spec, ok := x.Specs[i].(*ast.ValueSpec)
-		if ok {
-			for j := 0; j < len(spec.Names); j++ {
-				id := spec.Names[j]
-				t, ok := spec.Type.(*ast.SelectorExpr)
-				if ok {
-					tsel, ok := t.X.(*ast.Ident)
-					if ok {
-						if tsel.Name == "sync" && t.Sel.Name == "Cond" {
-							fmt.Printf("Found declaration of cond %s\n", id.Name)
-							v.addDef(createDecl(id.Name, Cond))
-							v.state.addCondDecl()
-						}
-					}
-				}
-			}
+func matchParallel(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Parallel" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Parallel on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addParallel(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addParallelFor(d.name, d)
 		}
 	}
 }
 
-func matchCondAssignDecl(x *ast.AssignStmt, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Rhs); i++ {
-		call, ok := x.Rhs[i].(*ast.CallExpr)
-		if ok {
-			t, ok := call.Fun.(*ast.SelectorExpr)
-			if ok {
-				tid, ok := t.X.(*ast.Ident)
-				if ok {
-					if tid.Name == "sync" && t.Sel.Name == "NewCond" {
-						id, ok := x.Lhs[0].(*ast.Ident)
-						if ok {
-							fmt.Printf("Found declaration of cond %s\n", id.Name)
-							v.addDef(createDecl(id.Name, Cond))
-							v.state.addCondDecl()
-						}
-					}
-				}
-			}
+func matchRunParallel(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "RunParallel" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of RunParallel on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addRunParallel(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addRunParallelFor(d.name, d)
 		}
 	}
 }
 
-func matchOnceParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Names); i++ {
-		fieldName := x.Names[i]
-
-		fieldType := getFieldType(x)
-
-		if fieldType != nil {
-			tsel, ok := fieldType.X.(*ast.Ident)
-			if ok {
-				if tsel.Name == "sync" && fieldType.Sel.Name == "Once" {
-					fmt.Printf("Found declaration of Once field %s\n", fieldName.Name)
-					v.addDef(createDecl(fieldName.Name, Once))
-					v.state.addOnceDecl()
-				}
-			}
+func matchSetParallelism(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "SetParallelism" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of SetParallelism on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addSetParallelism(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addSetParallelismFor(d.name, d)
 		}
 	}
 }
 
-func getFieldType(x *ast.Field) *ast.SelectorExpr {
-	fieldType, ok := x.Type.(*ast.SelectorExpr)
-	if ok {
-		return fieldType
-	} else {
-		starType, ok := x.Type.(*ast.StarExpr)
-		if ok {
-			innerFieldType, ok := starType.X.(*ast.SelectorExpr)
-			if ok {
-				return innerFieldType
-			}
+func matchNext(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Next" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Next on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addNext(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addNextFor(d.name, d)
 		}
 	}
-	return nil
 }
 
-func matchCondParamDecl(x *ast.Field, v *Visitor, n ast.Node) {
-	for i := 0; i < len(x.Names); i++ {
-		fieldName := x.Names[i]
-
-		fieldType := getFieldType(x)
-
-		if fieldType != nil {
-			tsel, ok := fieldType.X.(*ast.Ident)
-			if ok {
-				if tsel.Name == "sync" && fieldType.Sel.Name == "Cond" {
-					fmt.Printf("Found declaration of cond field %s\n", fieldName.Name)
-					v.addDef(createDecl(fieldName.Name, Cond))
-					v.state.addCondDecl()
-				}
-			}
+func matchStop(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "Stop" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function such as signal.Stop, not a method
+			return
+		}
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of Stop on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addStop(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addStopFor(d.name, d)
 		}
 	}
 }
 
-func matchDone(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+func matchReset(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
 	funName := x.Sel
-	if funName.Name == "Done" {
+	if funName.Name == "Reset" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package function such as signal.Stop, not a method
+			return
+		}
 		var buf bytes.Buffer
 		printer.Fprint(&buf, v.fset, x.X)
-		fmt.Printf("Found call of Done on node %s\n", buf.String())
-		v.state.addDone(buf.String())
+		fmt.Printf("Found call of Reset on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addReset(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addResetFor(d.name, d)
+		}
 	}
 }
 
@@ -936,7 +6823,11 @@ func matchAdd(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
 		var buf bytes.Buffer
 		printer.Fprint(&buf, v.fset, x.X)
 		fmt.Printf("Found call of Add on node %s\n", buf.String())
-		v.state.addAdd(buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addAdd(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addAddFor(d.name, d)
+		}
 	}
 }
 
@@ -946,7 +6837,11 @@ func matchLock(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
 		var buf bytes.Buffer
 		printer.Fprint(&buf, v.fset, x.X)
 		fmt.Printf("Found call of Lock on node %s\n", buf.String())
-		v.state.addLock(buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addLock(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addLockFor(d.name, d)
+		}
 	}
 }
 
@@ -956,7 +6851,81 @@ func matchUnlock(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
 		var buf bytes.Buffer
 		printer.Fprint(&buf, v.fset, x.X)
 		fmt.Printf("Found call of Unlock on node %s\n", buf.String())
-		v.state.addUnlock(buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addUnlock(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addUnlockFor(d.name, d)
+		}
+	}
+}
+
+func matchRLock(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "RLock" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of RLock on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addRLock(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addRLockFor(d.name, d)
+		}
+	}
+}
+
+func matchRUnlock(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "RUnlock" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of RUnlock on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addRUnlock(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addRUnlockFor(d.name, d)
+		}
+	}
+}
+
+func matchRLocker(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "RLocker" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of RLocker on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addRLocker(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addRLockerFor(d.name, d)
+		}
+	}
+}
+
+func matchTryLock(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "TryLock" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of TryLock on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addTryLock(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addTryLockFor(d.name, d)
+		}
+	}
+}
+
+func matchTryRLock(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
+	funName := x.Sel
+	if funName.Name == "TryRLock" {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, v.fset, x.X)
+		fmt.Printf("Found call of TryRLock on node %s\n", buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addTryRLock(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addTryRLockFor(d.name, d)
+		}
 	}
 }
 
@@ -966,17 +6935,30 @@ func matchWait(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
 		var buf bytes.Buffer
 		printer.Fprint(&buf, v.fset, x.X)
 		fmt.Printf("Found call of Wait on node %s\n", buf.String())
-		v.state.addWait(buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addWait(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addWaitFor(d.name, d)
+		}
 	}
 }
 
 func matchSignal(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
 	funName := x.Sel
 	if funName.Name == "Signal" {
+		id, ok := x.X.(*ast.Ident)
+		if ok && id.Obj == nil && v.state.isImportName(id.Name) {
+			// A package member such as the os.Signal type, not a method
+			return
+		}
 		var buf bytes.Buffer
 		printer.Fprint(&buf, v.fset, x.X)
 		fmt.Printf("Found call of Signal on node %s\n", buf.String())
-		v.state.addSignal(buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addSignal(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addSignalFor(d.name, d)
+		}
 	}
 }
 
@@ -986,7 +6968,11 @@ func matchBroadcast(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
 		var buf bytes.Buffer
 		printer.Fprint(&buf, v.fset, x.X)
 		fmt.Printf("Found call of Broadcast on node %s\n", buf.String())
-		v.state.addBroadcast(buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addBroadcast(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addBroadcastFor(d.name, d)
+		}
 	}
 }
 
@@ -996,31 +6982,33 @@ func matchDo(x *ast.SelectorExpr, v *Visitor, n ast.Node) {
 		var buf bytes.Buffer
 		printer.Fprint(&buf, v.fset, x.X)
 		fmt.Printf("Found call of Do on node %s\n", buf.String())
-		v.state.addDo(buf.String())
+		if d, ok := v.getTypedDecl(x); !ok {
+			v.state.addDo(buf.String())
+		} else if d.typeof != Unknown {
+			v.state.addDoFor(d.name, d)
+		}
 	}
 }
 
 func matchNewCond(x *ast.CallExpr, v *Visitor, n ast.Node) {
-	target, ok := x.Fun.(*ast.SelectorExpr)
-	if ok {
-		targetName, ok := target.X.(*ast.Ident)
-		if ok {
-			funName := target.Sel
-			if funName.Name == "NewCond" && targetName.Name == "sync" {
-				fmt.Print("Found call of NewCond\n")
-				v.state.addCondNew()
-			}
-		}
+	if isNewCondCall(x, v) {
+		fmt.Print("Found call of NewCond\n")
+		v.state.addCondNew()
 	}
 }
 
 func (v *Visitor) Visit(n ast.Node) ast.Visitor {
+	if n != nil {
+		v.state.pos = n.Pos()
+	}
 	if v.mode {
 		if n == nil {
 			return nil
 		}
 
 		switch x := n.(type) {
+		case *ast.ImportSpec:
+			matchImportSpec(x, v, n)
 		case *ast.GenDecl:
 			matchWaitGroupDecl(x, v, n)
 			matchMutexDecl(x, v, n)
@@ -1028,6 +7016,38 @@ func (v *Visitor) Visit(n ast.Node) ast.Visitor {
 			matchLockerDecl(x, v, n)
 			matchOnceDecl(x, v, n)
 			matchCondDecl(x, v, n)
+			matchChanDecl(x, v, n)
+			matchContextDecl(x, v, n)
+			matchAtomicDecl(x, v, n)
+			matchSyncMapDecl(x, v, n)
+			matchPoolDecl(x, v, n)
+			matchInferredDecl(x, v, n)
+			matchXSyncDecl(x, v, n)
+			matchTimeDecl(x, v, n)
+			matchTypedVarDecl(x, v, n)
+			matchCollectionDecl(x, v, n)
+		case *ast.TypeSpec:
+			matchEmbeddedDecl(x, v, n)
+			matchGenericDecl(x, v, n)
+			if x.TypeParams != nil {
+				st, ok := x.Type.(*ast.StructType)
+				if ok {
+					matchTypeParamDecl(x.TypeParams, st.Fields, v)
+				}
+				// Type parameters are not declarations, so only the
+				// type itself is visited
+				ast.Walk(v, x.Type)
+				return nil
+			}
+		case *ast.FuncType:
+			if x.TypeParams != nil {
+				matchTypeParamDecl(x.TypeParams, x.Params, v)
+				ast.Walk(v, x.Params)
+				if x.Results != nil {
+					ast.Walk(v, x.Results)
+				}
+				return nil
+			}
 		case *ast.Field:
 			matchWaitGroupParamDecl(x, v, n)
 			matchMutexParamDecl(x, v, n)
@@ -1035,8 +7055,64 @@ func (v *Visitor) Visit(n ast.Node) ast.Visitor {
 			matchLockerParamDecl(x, v, n)
 			matchOnceParamDecl(x, v, n)
 			matchCondParamDecl(x, v, n)
+			matchChanParamDecl(x, v, n)
+			matchContextParamDecl(x, v, n)
+			matchAtomicParamDecl(x, v, n)
+			matchSyncMapParamDecl(x, v, n)
+			matchPoolParamDecl(x, v, n)
+			matchTypedVarParamDecl(x, v, n)
+			matchXSyncParamDecl(x, v, n)
+			matchTimeParamDecl(x, v, n)
+			matchTestingParamDecl(x, v, n)
+			matchCollectionParamDecl(x, v, n)
+			v.addLocals(identExprs(x.Names), nil, x.Type, false)
 		case *ast.AssignStmt:
 			matchCondAssignDecl(x, v, n)
+			if len(x.Lhs) == len(x.Rhs) {
+				for i := range x.Lhs {
+					matchCondLocker(x.Lhs[i], x.Rhs[i], false, v)
+				}
+			}
+			matchChanAssignDecl(x, v, n)
+			matchContextAssignDecl(x, v, n)
+			matchInferredAssignDecl(x, v, n)
+			matchTypedVarAssignDecl(x, v, n)
+			matchXSyncAssignDecl(x, v, n)
+			matchTimeAssignDecl(x, v, n)
+			matchCollectionAssignDecl(x, v, n)
+			matchAliasAssign(x.Lhs, x.Rhs, v)
+			if x.Tok == token.DEFINE {
+				v.addLocals(x.Lhs, x.Rhs, nil, false)
+			}
+		case *ast.CallExpr:
+			matchNewCondLocker(x, v, n)
+			matchAliasCall(x, v, n)
+		case *ast.KeyValueExpr:
+			matchCondLocker(x.Key, x.Value, true, v)
+		case *ast.RangeStmt:
+			scoped := v.enterScope(n)
+			matchCollectionRangeDecl(x, scoped, n)
+			scoped.addLocals(rangeLoopVars(x), nil, nil, true)
+			return scoped
+		case *ast.ForStmt:
+			scoped := v.enterScope(n)
+			scoped.addLocals(forLoopVars(x), nil, nil, true)
+			return scoped
+		case *ast.FuncDecl, *ast.FuncLit, *ast.BlockStmt, *ast.IfStmt,
+			*ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.CaseClause, *ast.CommClause,
+			*ast.StructType:
+			if fd, ok := n.(*ast.FuncDecl); ok {
+				matchAliasFuncDecl(fd, v, n)
+			}
+			return v.enterScope(n)
+		case *ast.ValueSpec:
+			if len(x.Names) == len(x.Values) {
+				for i := range x.Names {
+					matchCondLocker(x.Names[i], x.Values[i], false, v)
+				}
+			}
+			matchAliasAssign(identExprs(x.Names), x.Values, v)
+			v.addLocals(identExprs(x.Names), x.Values, x.Type, false)
 		}
 		return v
 	} else {
@@ -1046,22 +7122,112 @@ func (v *Visitor) Visit(n ast.Node) ast.Visitor {
 
 		switch x := n.(type) {
 		case *ast.CallExpr:
+			matchMethodCall(x, v, n)
 			matchNewCond(x, v, n)
-		//case *ast.CallExpr:
-		//	matchMakeCall(x, v, n)
-		//case *ast.SendStmt:
-		//	matchSendStmt(x, v, n)
-		//case *ast.UnaryExpr:
-		//	matchUnaryExpr(x, v, n)
+			matchMakeCall(x, v, n)
+			matchCloseCall(x, v, n)
+			matchContextCall(x, v, n)
+			matchAtomicCall(x, v, n)
+			matchErrGroupWithContext(x, v, n)
+			matchTimeCall(x, v, n)
+			matchRuntimeCall(x, v, n)
+			matchOnceFuncCall(x, v, n)
+			matchSignalCall(x, v, n)
+			matchIndexedCall(x, v, n)
+			matchLegacyOnceValue(x, v, n)
+			matchMethodValueCall(x, v, n)
+		case *ast.BlockStmt:
+			matchLegacyWaitGroupGo(x.List, v)
+		case *ast.CaseClause:
+			matchLegacyWaitGroupGo(x.Body, v)
+		case *ast.CommClause:
+			matchLegacyWaitGroupGo(x.Body, v)
+		case *ast.ExprStmt:
+			matchDiscardedTicker(x, v, n)
+		case *ast.SendStmt:
+			matchSendStmt(x, v, n)
+		case *ast.UnaryExpr:
+			matchUnaryExpr(x, v, n)
+		case *ast.AssignStmt:
+			matchRecvOkAssign(x, v, n)
+			matchPoolNewAssign(x, v, n)
+		case *ast.CompositeLit:
+			matchPoolLiteral(x, v, n)
+		case *ast.GenDecl:
+			matchRecvOkDecl(x, v, n)
+		case *ast.RangeStmt:
+			matchRangeStmt(x, v, n)
+			return v.enterLoop()
+		case *ast.ForStmt:
+			return v.enterLoop()
+		case *ast.FuncLit:
+			return v.enterFuncLit()
+		case *ast.FuncDecl:
+			kind := getTestFuncKind(x, v)
+			if kind != "" {
+				fmt.Printf("Found %s function %s\n", strings.ToLower(kind), x.Name.Name)
+				return v.enterTestFunc(kind)
+			}
+			if x.Recv != nil {
+				// The receiver type of a method of a generic type, such
+				// as SafeMap[K, V], is not an instantiation
+				ast.Walk(v, x.Type)
+				if x.Body != nil {
+					ast.Walk(v, x.Body)
+				}
+				return nil
+			}
+		case *ast.IndexExpr:
+			matchGenericInst(x, v, n)
+		case *ast.IndexListExpr:
+			matchGenericInst(x, v, n)
+		case *ast.GoStmt:
+			matchGoStmt(x, v, n)
+		case *ast.SelectStmt:
+			matchSelectStmt(x, v, n)
 		case *ast.SelectorExpr:
+			if v.state.methodValues[x] {
+				fmt.Printf("Found method value %s, counted where it is called\n", x.Sel.Name)
+				return v
+			}
 			matchDone(x, v, n)
+			matchErr(x, v, n)
 			matchAdd(x, v, n)
 			matchWait(x, v, n)
 			matchLock(x, v, n)
 			matchUnlock(x, v, n)
+			matchRLock(x, v, n)
+			matchRUnlock(x, v, n)
+			matchRLocker(x, v, n)
+			matchTryLock(x, v, n)
+			matchTryRLock(x, v, n)
 			matchSignal(x, v, n)
 			matchBroadcast(x, v, n)
 			matchDo(x, v, n)
+			matchLoad(x, v, n)
+			matchStore(x, v, n)
+			matchSwap(x, v, n)
+			matchCompareAndSwap(x, v, n)
+			matchLoadOrStore(x, v, n)
+			matchLoadAndDelete(x, v, n)
+			matchDelete(x, v, n)
+			matchRange(x, v, n)
+			matchGet(x, v, n)
+			matchPut(x, v, n)
+			matchGo(x, v, n)
+			matchTryGo(x, v, n)
+			matchSetLimit(x, v, n)
+			matchAcquire(x, v, n)
+			matchTryAcquire(x, v, n)
+			matchRelease(x, v, n)
+			matchDoChan(x, v, n)
+			matchForget(x, v, n)
+			matchStop(x, v, n)
+			matchReset(x, v, n)
+			matchParallel(x, v, n)
+			matchRunParallel(x, v, n)
+			matchSetParallelism(x, v, n)
+			matchNext(x, v, n)
 		}
 		return v
 	}
@@ -0,0 +1,1494 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// writeFiles writes the files, keyed by their path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// analyzeFiles analyzes the files of a directory together, as processDir
+// does, and returns the columns of the row of each file by name.
+func analyzeFiles(t *testing.T, files map[string]string, options Options) map[string]map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	var paths []string
+	for name := range files {
+		paths = append(paths, filepath.Join(dir, filepath.FromSlash(name)))
+	}
+	sort.Strings(paths)
+	res := map[string]map[string]string{}
+	for path, row := range processPackages(paths, options) {
+		columns := map[string]string{}
+		for i, header := range stateHeaders() {
+			columns[header] = row[i]
+		}
+		name, _ := filepath.Rel(dir, path)
+		res[filepath.ToSlash(name)] = columns
+	}
+	return res
+}
+
+// analyzeFile analyzes the file name among the files, as processFile
+// does, and returns the columns of its row.
+func analyzeFile(t *testing.T, files map[string]string, name string, options Options) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	processFile(filepath.Join(dir, filepath.FromSlash(name)), writer, options)
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(records) != 1 {
+		t.Fatalf("got %d rows, %v", len(records), err)
+	}
+	columns := map[string]string{}
+	for i, header := range stateHeaders() {
+		columns[header] = records[0][i]
+	}
+	return columns
+}
+
+// analyze analyzes a single file and returns the columns of its row.
+func analyze(t *testing.T, src string, options Options) map[string]string {
+	t.Helper()
+	return analyzeFiles(t, map[string]string{"a.go": src}, options)["a.go"]
+}
+
+// counterTest is a source file and the values expected in some of the
+// columns of its row.
+type counterTest struct {
+	name string
+	src  string
+	want map[string]string
+}
+
+func runCounterTests(t *testing.T, tests []counterTest, options Options) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns := analyze(t, test.src, options)
+			checkColumns(t, columns, test.want)
+		})
+	}
+}
+
+func checkColumns(t *testing.T, columns map[string]string, want map[string]string) {
+	t.Helper()
+	for column, value := range want {
+		got, ok := columns[column]
+		if !ok {
+			t.Errorf("no column %s", column)
+		} else if got != value {
+			t.Errorf("%s = %s, want %s", column, got, value)
+		}
+	}
+}
+
+func TestChannelDecls(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "make",
+			src: `package p
+
+func f(n int) {
+	a := make(chan int)
+	b := make(chan string, 10)
+	c := make(chan bool, n)
+	_, _, _ = a, b, c
+}
+`,
+			want: map[string]string{"chanDecls": "3", "bidiChanDecls": "3", "chanMake": "3",
+				"unbufferedMake": "1", "literalBufMake": "1", "computedBufMake": "1"},
+		},
+		{
+			name: "directions",
+			src: `package p
+
+type pool struct {
+	jobs chan int
+}
+
+var done chan struct{}
+
+func f(in <-chan int, out chan<- int) {}
+`,
+			want: map[string]string{"chanDecls": "4", "bidiChanDecls": "2", "sendChanDecls": "1",
+				"recvChanDecls": "1", "chanMake": "0"},
+		},
+	}, Options{})
+}
+
+func TestChannelOps(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "ops",
+			src: `package p
+
+func f() {
+	ch := make(chan int, 1)
+	ch <- 1
+	<-ch
+	v, ok := <-ch
+	for x := range ch {
+		_ = x
+	}
+	close(ch)
+	_, _ = v, ok
+}
+`,
+			want: map[string]string{"chanSend": "1", "chanRecv": "2", "chanRecvOk": "1",
+				"chanRange": "1", "chanClose": "1", "unknownSend": "0", "unknownRecv": "0"},
+		},
+		{
+			name: "unknown",
+			src: `package p
+
+func f(c interface{ C() chan int }) {
+	c.C() <- 1
+	close(c.C())
+}
+`,
+			want: map[string]string{"chanSend": "0", "unknownSend": "1", "unknownClose": "1"},
+		},
+	}, Options{})
+}
+
+func TestGoStmts(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "targets",
+			src: `package p
+
+type S struct{}
+
+func (S) run() {}
+
+func work(i int) {}
+
+func f(s S, xs []int) {
+	go work(1)
+	go s.run()
+	go func() {}()
+	for i := range xs {
+		go work(i)
+	}
+}
+`,
+			want: map[string]string{"goStmts": "4", "goNamedFunc": "2", "goMethod": "1", "goClosure": "1",
+				"goInLoop": "1", "goLoopVarArg": "1", "goLoopVarCapture": "0"},
+		},
+		{
+			name: "argument expressions",
+			src: `package p
+
+type T struct{ i int }
+
+func work(int)     {}
+func ref(*int)     {}
+func field(t T)    {}
+
+func f(xs []int, t T) {
+	for i, x := range xs {
+		go ref(&x)
+		go work(x + 1)
+		go work(xs[i])
+		go work(t.i)
+		go field(T{i: 0})
+		go field(T{x})
+	}
+}
+`,
+			want: map[string]string{"goInLoop": "6", "goLoopVarArg": "4"},
+		},
+		{
+			name: "capture",
+			src: `package p
+
+func use(int) {}
+
+func f(xs []int) {
+	for i := range xs {
+		go func() {
+			use(i)
+		}()
+	}
+}
+`,
+			want: map[string]string{"goInLoop": "1", "goLoopVarCapture": "1"},
+		},
+		{
+			name: "shadowed",
+			src: `package p
+
+type T struct{ i int }
+
+func use(int) {}
+
+func f(xs []int, t T) {
+	for i := range xs {
+		go func() {
+			use(t.i)
+		}()
+		go func() {
+			i := 0
+			use(i)
+		}()
+		go func(i int) {
+			use(i)
+		}(0)
+	}
+	for _, v := range xs {
+		v := v
+		go func() {
+			use(v)
+		}()
+	}
+}
+`,
+			want: map[string]string{"goInLoop": "4", "goLoopVarCapture": "0"},
+		},
+		{
+			name: "closure defined in loop",
+			src: `package p
+
+func f(xs []int) []func() {
+	var fs []func()
+	for range xs {
+		fs = append(fs, func() {
+			go func() {}()
+		})
+	}
+	return fs
+}
+`,
+			want: map[string]string{"goStmts": "1", "goInLoop": "0"},
+		},
+	}, Options{})
+}
+
+func TestSelectStmts(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "cases",
+			src: `package p
+
+import "time"
+
+func f(in, out chan int) {
+	for {
+		select {
+		case v := <-in:
+			out <- v
+		case out <- 1:
+		case <-time.After(time.Second):
+		default:
+		}
+	}
+}
+`,
+			want: map[string]string{"selectStmts": "1", "selectCases": "4", "selectSendCases": "1",
+				"selectRecvCases": "2", "selectDefault": "1", "selectTimerCases": "1", "selectInLoop": "1"},
+		},
+		{
+			name: "empty and single",
+			src: `package p
+
+func f(in chan int) {
+	select {
+	case <-in:
+	}
+	select {}
+}
+`,
+			want: map[string]string{"selectStmts": "2", "selectEmpty": "1", "selectSingleCase": "1",
+				"selectInLoop": "0"},
+		},
+		{
+			name: "closure defined in loop",
+			src: `package p
+
+func f(in chan int, fs []func()) {
+	for i := range fs {
+		fs[i] = func() {
+			select {
+			case <-in:
+			default:
+			}
+		}
+	}
+}
+`,
+			want: map[string]string{"selectStmts": "1", "selectInLoop": "0"},
+		},
+	}, Options{})
+}
+
+func TestContext(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "constructors and calls",
+			src: `package p
+
+import (
+	"context"
+	"time"
+)
+
+func f(parent context.Context) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	tctx, tcancel := context.WithTimeout(ctx, time.Second)
+	defer tcancel()
+	dctx, dcancel := context.WithDeadline(ctx, time.Now())
+	defer dcancel()
+	vctx := context.WithValue(ctx, "k", 1)
+	cctx, ccancel := context.WithCancelCause(ctx)
+	defer ccancel(nil)
+	_, _, _ = dctx, vctx, cctx
+	<-tctx.Done()
+	return ctx.Err()
+}
+`,
+			want: map[string]string{"ctxWithCancel": "1", "ctxWithTimeout": "1", "ctxWithDeadline": "1",
+				"ctxWithValue": "1", "ctxWithCause": "1", "ctxDone": "1", "ctxErr": "1",
+				"unknownDone": "0", "waitGroupDone": "0"},
+		},
+	}, Options{})
+}
+
+func TestAtomic(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "functions",
+			src: `package p
+
+import "sync/atomic"
+
+var n int64
+
+func f() {
+	atomic.AddInt64(&n, 1)
+	atomic.LoadInt64(&n)
+	atomic.StoreInt64(&n, 2)
+	atomic.SwapInt64(&n, 3)
+	atomic.CompareAndSwapInt64(&n, 3, 4)
+}
+`,
+			want: map[string]string{"atomicFuncAdd": "1", "atomicFuncLoad": "1", "atomicFuncStore": "1",
+				"atomicFuncSwap": "1", "atomicFuncCAS": "1"},
+		},
+		{
+			name: "typed",
+			src: `package p
+
+import "sync/atomic"
+
+type Config struct{}
+
+type S struct {
+	n    atomic.Int64
+	ok   atomic.Bool
+	cfg  atomic.Pointer[Config]
+	last atomic.Value
+}
+
+func (s *S) f() {
+	s.n.Add(1)
+	s.n.Load()
+	s.ok.Store(true)
+	s.cfg.Swap(nil)
+	s.ok.CompareAndSwap(true, false)
+	s.last.Store(1)
+	s.last.Load()
+}
+`,
+			want: map[string]string{"atomicTypedDecls": "3", "atomicValueDecls": "1", "atomicTypedAdd": "1",
+				"atomicTypedLoad": "1", "atomicTypedStore": "1", "atomicTypedSwap": "1", "atomicTypedCAS": "1",
+				"atomicValueStore": "1", "atomicValueLoad": "1", "unknownLoad": "0", "unknownStore": "0"},
+		},
+	}, Options{})
+}
+
+func TestSyncMapAndPool(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "calls",
+			src: `package p
+
+import "sync"
+
+var m sync.Map
+
+var pool = sync.Pool{New: func() any { return new(int) }}
+
+func f() {
+	m.Store("k", 1)
+	m.Load("k")
+	m.LoadOrStore("k", 2)
+	m.LoadAndDelete("k")
+	m.Delete("k")
+	m.Range(func(k, v any) bool { return true })
+	m.CompareAndSwap("k", 1, 2)
+	x := pool.Get()
+	pool.Put(x)
+}
+`,
+			want: map[string]string{"syncMapDecls": "1", "poolDecls": "1", "syncMapStore": "1", "syncMapLoad": "1",
+				"syncMapLoadStore": "1", "syncMapLoadDel": "1", "syncMapDelete": "1", "syncMapRange": "1",
+				"syncMapCAS": "1", "poolGet": "1", "poolPut": "1", "poolNew": "1"},
+		},
+		{
+			name: "unrelated Get",
+			src: `package p
+
+import (
+	"net/http"
+	"time"
+)
+
+type Server struct {
+	client *http.Client
+}
+
+func (s *Server) f(url string) {
+	http.Get(url)
+	client := http.Client{Timeout: 10 * time.Second}
+	client.Get(url)
+	s.client.Get(url)
+}
+
+func g(h http.Header, store interface{ Load(string) any }) {
+	h.Get("k")
+	store.Load("k")
+}
+`,
+			want: map[string]string{"poolGet": "0", "unknownGet": "0", "unknownLoad": "0"},
+		},
+		{
+			name: "unresolved receiver",
+			src: `package p
+
+func lookup() interface{ Get() any } { return nil }
+
+func f() {
+	x := lookup()
+	x.Get()
+}
+`,
+			want: map[string]string{"unknownGet": "1"},
+		},
+	}, Options{})
+}
+
+func TestRWMutexReadSide(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "read side and TryLock",
+			src: `package p
+
+import "sync"
+
+var rw sync.RWMutex
+
+var mu sync.Mutex
+
+func f() {
+	rw.RLock()
+	rw.RUnlock()
+	rw.Lock()
+	rw.Unlock()
+	rw.TryLock()
+	rw.TryRLock()
+	l := rw.RLocker()
+	_ = l
+	mu.TryLock()
+}
+`,
+			want: map[string]string{"rwMutexDecls": "1", "mutexDecls": "1", "rwMutexRLock": "1",
+				"rwMutexRUnlock": "1", "rwMutexLock": "1", "rwMutexUnlock": "1", "rwMutexTryLock": "1",
+				"rwMutexTryRLock": "1", "rwMutexRLocker": "1", "mutexTryLock": "1"},
+		},
+	}, Options{})
+}
+
+func TestInferredDecls(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "literals and new",
+			src: `package p
+
+import "sync"
+
+var once = sync.Once{}
+
+func f() {
+	wg := sync.WaitGroup{}
+	mu := new(sync.Mutex)
+	m := &sync.RWMutex{}
+	c := sync.NewCond(&sync.Mutex{})
+	_, _, _, _ = wg, mu, m, c
+}
+`,
+			want: map[string]string{"onceDecls": "1", "waitGroupDecls": "1", "mutexDecls": "2",
+				"rwMutexDecls": "1", "condDecls": "1"},
+		},
+	}, Options{})
+}
+
+func TestEmbeddedPrimitives(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "promoted calls",
+			src: `package p
+
+import "sync"
+
+type Cache struct {
+	sync.Mutex
+	data map[string]string
+}
+
+type Store struct {
+	*sync.RWMutex
+}
+
+type Group struct {
+	sync.WaitGroup
+}
+
+func f(c *Cache, s Store, g Group) {
+	c.Lock()
+	c.Unlock()
+	s.RLock()
+	g.Add(1)
+	g.Wait()
+}
+`,
+			want: map[string]string{"mutexDecls": "1", "rwMutexDecls": "1", "waitGroupDecls": "1",
+				"mutexLock": "1", "mutexUnlock": "1", "rwMutexRLock": "1", "waitGroupAdd": "1",
+				"waitGroupWait": "1", "unknownLock": "0", "unknownRLock": "0"},
+		},
+	}, Options{})
+}
+
+func TestImportNames(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "alias",
+			src: `package p
+
+import xsync "sync"
+
+var mu xsync.Mutex
+
+func f() {
+	mu.Lock()
+	mu.Unlock()
+}
+`,
+			want: map[string]string{"mutexDecls": "1", "mutexLock": "1", "mutexUnlock": "1"},
+		},
+		{
+			name: "dot import",
+			src: `package p
+
+import . "sync"
+
+var wg WaitGroup
+
+func f() {
+	wg.Add(1)
+	wg.Wait()
+}
+`,
+			want: map[string]string{"waitGroupDecls": "1", "waitGroupAdd": "1", "waitGroupWait": "1"},
+		},
+		{
+			name: "shadowed",
+			src: `package p
+
+type fake struct{ Mutex int }
+
+func f() {
+	sync := fake{}
+	var x = sync.Mutex
+	_ = x
+}
+`,
+			want: map[string]string{"mutexDecls": "0"},
+		},
+	}, Options{})
+}
+
+func TestXSync(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "calls",
+			src: `package p
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/sync/singleflight"
+)
+
+var sf singleflight.Group
+
+func f(ctx context.Context) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(2)
+	g.Go(func() error { return nil })
+	g.TryGo(func() error { return nil })
+	sem := semaphore.NewWeighted(2)
+	sem.Acquire(gctx, 1)
+	sem.TryAcquire(1)
+	sem.Release(1)
+	sf.Do("k", func() (any, error) { return nil, nil })
+	sf.DoChan("k", func() (any, error) { return nil, nil })
+	sf.Forget("k")
+	return g.Wait()
+}
+`,
+			want: map[string]string{"errGroupWithCtx": "1", "errGroupSetLimit": "1", "errGroupGo": "1",
+				"errGroupTryGo": "1", "errGroupWait": "1", "semAcquire": "1", "semTryAcquire": "1",
+				"semRelease": "1", "singleFlightDo": "1", "singleFlightChan": "1", "singleFlightFgt": "1",
+				"unknownWait": "0", "unknownDo": "0"},
+		},
+		{
+			name: "unrelated Go and Release",
+			src: `package p
+
+import "example.com/pool"
+
+type Handle struct{}
+
+func (Handle) Release() {}
+
+type Runner struct{}
+
+func (Runner) Go(func()) {}
+
+func f(h Handle) {
+	pool.Go(func() {})
+	pool.Release()
+	h.Release()
+	r := Runner{}
+	r.Go(func() {})
+}
+`,
+			want: map[string]string{"errGroupGo": "0", "semRelease": "0", "unknownGo": "0",
+				"unknownRelease": "0"},
+		},
+	}, Options{})
+}
+
+func TestTimers(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "constructors and calls",
+			src: `package p
+
+import "time"
+
+func f(in chan int) {
+	timer := time.NewTimer(time.Second)
+	timer.Reset(time.Second)
+	timer.Stop()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	time.AfterFunc(time.Second, func() {})
+	<-time.Tick(time.Second)
+	for {
+		select {
+		case <-in:
+		case <-time.After(time.Second):
+		}
+	}
+}
+`,
+			want: map[string]string{"timeNewTimer": "1", "timerReset": "1", "timerStop": "1",
+				"timeNewTicker": "1", "tickerStop": "1", "tickerNotStopped": "0", "timeAfterFunc": "1",
+				"timeTick": "1", "timeAfter": "1", "timeAfterInLoop": "1"},
+		},
+		{
+			name: "returned and passed tickers",
+			src: `package p
+
+import "time"
+
+func newTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+func watch(t *time.Ticker) {
+	defer t.Stop()
+}
+
+func f() {
+	watch(time.NewTicker(time.Second))
+}
+`,
+			want: map[string]string{"timeNewTicker": "2", "tickerNotStopped": "0"},
+		},
+		{
+			name: "discarded ticker",
+			src: `package p
+
+import "time"
+
+func f() {
+	time.NewTicker(time.Second)
+}
+`,
+			want: map[string]string{"timeNewTicker": "1", "tickerNotStopped": "1"},
+		},
+		{
+			name: "same name in different functions",
+			src: `package p
+
+import "time"
+
+func f() {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+}
+
+func g() {
+	t := time.NewTicker(time.Second)
+	<-t.C
+}
+`,
+			want: map[string]string{"timeNewTicker": "2", "tickerStop": "1", "tickerNotStopped": "1"},
+		},
+		{
+			name: "field ticker",
+			src: `package p
+
+import "time"
+
+type S struct {
+	ticker *time.Ticker
+}
+
+func (s *S) start() {
+	s.ticker = time.NewTicker(time.Second)
+}
+
+func (s *S) stop() {
+	s.ticker.Stop()
+}
+`,
+			want: map[string]string{"tickerDecls": "1", "tickerStop": "1", "tickerNotStopped": "0"},
+		},
+	}, Options{})
+}
+
+func TestRuntime(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "scheduling calls",
+			src: `package p
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+func f() {
+	runtime.GOMAXPROCS(2)
+	runtime.Gosched()
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	_ = runtime.NumGoroutine()
+	debug.SetMaxThreads(100)
+	runtime.Goexit()
+}
+`,
+			want: map[string]string{"rtGOMAXPROCS": "1", "rtGosched": "1", "rtLockOSThread": "1",
+				"rtUnlockOSThread": "1", "rtNumGoroutine": "1", "dbgSetMaxThreads": "1", "rtGoexit": "1"},
+		},
+	}, Options{})
+}
+
+func TestModernSync(t *testing.T) {
+	const src = `package p
+
+import "sync"
+
+type S struct {
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+var v int
+
+func (s *S) f() {
+	init := sync.OnceFunc(func() {})
+	value := sync.OnceValue(func() int { return 1 })
+	values := sync.OnceValues(func() (int, error) { return 1, nil })
+	init()
+	_, _ = value(), values
+	s.once.Do(func() { v = 1 })
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+	}()
+	s.wg.Wait()
+}
+`
+	tests := []struct {
+		name string
+		mod  string
+		want map[string]string
+	}{
+		{"no go.mod", "", map[string]string{"goVersion": "", "adoptOnceFunc": "0", "adoptWgGo": "0"}},
+		{"go 1.20", "module m\n\ngo 1.20\n", map[string]string{"goVersion": "1.20", "adoptOnceFunc": "0", "adoptWgGo": "0"}},
+		{"go 1.21", "module m\n\ngo 1.21.3\n", map[string]string{"goVersion": "1.21.3", "adoptOnceFunc": "1", "adoptWgGo": "0"}},
+		{"go 1.25", "module m\n\ngo 1.25\n", map[string]string{"goVersion": "1.25", "adoptOnceFunc": "1", "adoptWgGo": "1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files := map[string]string{"a.go": src}
+			if test.mod != "" {
+				files["go.mod"] = test.mod
+			}
+			columns := analyzeFiles(t, files, Options{})["a.go"]
+			checkColumns(t, columns, map[string]string{"onceFunc": "1", "onceValue": "1", "onceValues": "1",
+				"onceDo": "1", "legacyOnceValue": "1", "legacyWgGo": "1"})
+			checkColumns(t, columns, test.want)
+		})
+	}
+}
+
+func TestTestingConcurrency(t *testing.T) {
+	files := map[string]string{
+		"a.go": `package p
+
+func f() {
+	go func() {}()
+}
+`,
+		"a_test.go": `package p
+
+import "testing"
+
+func TestF(t *testing.T) {
+	t.Parallel()
+	t.Run("sub", func(t *testing.T) {
+		t.Parallel()
+		go f()
+	})
+	go f()
+}
+
+func BenchmarkF(b *testing.B) {
+	b.SetParallelism(4)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			f()
+		}
+	})
+	go f()
+}
+
+func helper(t *testing.T) {
+	go f()
+}
+`,
+	}
+	res := analyzeFiles(t, files, Options{})
+	checkColumns(t, res["a.go"], map[string]string{"testFile": "false", "goInTest": "0", "goInBenchmark": "0"})
+	checkColumns(t, res["a_test.go"], map[string]string{"testFile": "true", "testParallel": "2",
+		"benchSetParallel": "1", "benchRunParallel": "1", "pbNext": "1", "goInTest": "2", "goInBenchmark": "1"})
+}
+
+func TestSignals(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "calls",
+			src: `package p
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func f() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	signal.Stop(c)
+	signal.Ignore(syscall.SIGHUP)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	<-ctx.Done()
+}
+`,
+			want: map[string]string{"signalNotify": "1", "signalStop": "1", "signalIgnore": "1",
+				"signalNotifyCtx": "1", "signalUnbuffered": "0"},
+		},
+		{
+			name: "unbuffered",
+			src: `package p
+
+import (
+	"os"
+	"os/signal"
+)
+
+func f() {
+	c := make(chan os.Signal)
+	signal.Notify(c, os.Interrupt)
+	signal.Notify(make(chan os.Signal), os.Kill)
+	d := make(chan os.Signal, 1)
+	signal.Notify(d)
+}
+`,
+			want: map[string]string{"signalNotify": "3", "signalUnbuffered": "2"},
+		},
+		{
+			name: "assigned channels",
+			src: `package p
+
+import (
+	"os"
+	"os/signal"
+)
+
+type App struct {
+	sigs chan os.Signal
+	done chan os.Signal
+}
+
+func (a *App) Run() {
+	a.sigs = make(chan os.Signal)
+	signal.Notify(a.sigs, os.Interrupt)
+	a.done = make(chan os.Signal, 1)
+	signal.Notify(a.done, os.Kill)
+	var c chan os.Signal
+	c = make(chan os.Signal)
+	signal.Notify(c)
+}
+`,
+			want: map[string]string{"signalNotify": "3", "signalUnbuffered": "2"},
+		},
+	}, Options{})
+}
+
+func TestGenerics(t *testing.T) {
+	const src = `package p
+
+import "sync"
+
+type SafeMap[K comparable, V any] struct {
+	mu sync.Mutex
+	m  map[K]V
+}
+
+func (s *SafeMap[K, V]) Set(k K, v V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[k] = v
+}
+
+type Box[T any] struct {
+	v T
+}
+
+func With[L sync.Locker](l L) {
+	l.Lock()
+	l.Unlock()
+}
+
+var a SafeMap[string, int]
+var b = &SafeMap[string, int]{}
+var c SafeMap[int, string]
+var d Box[int]
+
+func f() {
+	a.Set("x", 1)
+}
+`
+	tests := []struct {
+		name    string
+		options Options
+		want    map[string]string
+	}{
+		{"per type", Options{}, map[string]string{"genericTypes": "1", "genericInsts": "2", "mutexDecls": "1", "lockerDecls": "1"}},
+		{"per instantiation", Options{perInstantiation: true}, map[string]string{"genericTypes": "1", "genericInsts": "2", "mutexDecls": "3"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			columns := analyze(t, src, test.options)
+			checkColumns(t, columns, map[string]string{"mutexLock": "1", "mutexUnlock": "1", "lockerLock": "1", "lockerUnlock": "1"})
+			checkColumns(t, columns, test.want)
+		})
+	}
+}
+
+func TestCondLockers(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "mutex locker",
+			src: `package p
+
+import "sync"
+
+type Button struct {
+	Clicked *sync.Cond
+}
+
+func f() {
+	var mu sync.Mutex
+	c := sync.NewCond(&mu)
+	d := sync.NewCond(&mu)
+	b := Button{Clicked: sync.NewCond(&sync.Mutex{})}
+	c.L.Lock()
+	c.Wait()
+	c.L.Unlock()
+	d.Signal()
+	b.Clicked.L.Lock()
+	b.Clicked.L.Unlock()
+}
+`,
+			want: map[string]string{"condLockers": "2", "condSharedLocker": "1", "condLock": "2",
+				"condUnlock": "2", "mutexLock": "2", "mutexUnlock": "2"},
+		},
+		{
+			name: "read locker",
+			src: `package p
+
+import "sync"
+
+func f() {
+	var rw sync.RWMutex
+	c := sync.NewCond(rw.RLocker())
+	c.L.Lock()
+	c.L.Unlock()
+}
+`,
+			want: map[string]string{"condLockers": "1", "condLock": "1", "condUnlock": "1",
+				"rwMutexRLock": "1", "rwMutexRUnlock": "1", "rwMutexLock": "0"},
+		},
+	}, Options{})
+}
+
+func TestCollections(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "collections",
+			src: `package p
+
+import "sync"
+
+type shard struct {
+	mu sync.Mutex
+}
+
+type bucket struct {
+	sync.RWMutex
+}
+
+var locks [16]sync.Mutex
+var shards []shard
+var buckets []bucket
+var groups map[string]*sync.WaitGroup
+var chans []chan int
+
+func f(h, i int) {
+	locks[i].Lock()
+	defer locks[i].Unlock()
+	shards[h%len(shards)].mu.Lock()
+	buckets[i].RLock()
+	groups["a"].Wait()
+	for _, mu := range locks {
+		mu.Lock()
+	}
+}
+`,
+			want: map[string]string{"primArrayDecls": "1", "primSliceDecls": "1", "primMapDecls": "1",
+				"indexedCalls": "5", "mutexLock": "3", "mutexUnlock": "1", "rwMutexRLock": "1", "waitGroupWait": "1"},
+		},
+	}, Options{})
+}
+
+func TestTypeCheck(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "receivers without names",
+			src: `package p
+
+import "sync"
+
+type S struct {
+	rw   sync.RWMutex
+	pool sync.Pool
+	cond *sync.Cond
+}
+
+func (s *S) lock() *sync.RWMutex { return &s.rw }
+func (s *S) p() *sync.Pool       { return &s.pool }
+func (s *S) c() *sync.Cond       { return s.cond }
+func group() *sync.WaitGroup     { return nil }
+
+func f(s *S) {
+	s.lock().RLock()
+	s.lock().RUnlock()
+	s.lock().TryLock()
+	group().Add(1)
+	s.c().Signal()
+	s.c().Broadcast()
+	s.p().Put(s.p().Get())
+}
+`,
+			want: map[string]string{"rwMutexRLock": "1", "rwMutexRUnlock": "1", "rwMutexTryLock": "1",
+				"waitGroupAdd": "1", "condSignal": "1", "condBroadcast": "1", "poolGet": "1", "poolPut": "1",
+				"unknownRLock": "0", "unknownAdd": "0", "unknownGet": "0", "typedCalls": "8", "typeFallbacks": "0"},
+		},
+		{
+			name: "same name different types",
+			src: `package p
+
+import "sync"
+
+type A struct{ mu sync.Mutex }
+type B struct{ mu sync.RWMutex }
+
+func f(a *A, b *B) {
+	a.mu.Lock()
+	b.mu.Lock()
+	b.mu.RLock()
+}
+`,
+			want: map[string]string{"mutexLock": "1", "rwMutexLock": "1", "rwMutexRLock": "1", "unknownLock": "0",
+				"unknownRLock": "0"},
+		},
+		{
+			name: "fallbacks",
+			src: `package p
+
+import (
+	"os/signal"
+
+	"golang.org/x/sync/errgroup"
+)
+
+type Client struct{}
+
+func (c *Client) Get(url string) {}
+
+type S struct {
+	Done chan struct{}
+}
+
+func f(s *S, url string) {
+	<-s.Done
+	signal.Reset()
+	client := &Client{}
+	client.Get(url)
+	var g errgroup.Group
+	g.Go(func() error { return nil })
+}
+`,
+			want: map[string]string{"typeFallbacks": "1", "errGroupGo": "1", "unknownGet": "0", "unknownDone": "0",
+				"unknownReset": "0"},
+		},
+	}, Options{typeCheck: true})
+}
+
+func TestScopes(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "same name in two functions",
+			src: `package p
+
+import "sync"
+
+func f() {
+	var mu sync.Mutex
+	mu.Lock()
+	mu.Unlock()
+}
+
+func g() {
+	var mu sync.RWMutex
+	mu.Lock()
+	mu.RLock()
+}
+`,
+			want: map[string]string{"mutexLock": "1", "mutexUnlock": "1", "rwMutexLock": "1", "rwMutexRLock": "1",
+				"unknownLock": "0"},
+		},
+		{
+			name: "local shadows global",
+			src: `package p
+
+import "sync"
+
+var mu sync.RWMutex
+
+func f() {
+	mu.Lock()
+	{
+		var mu sync.Mutex
+		mu.Lock()
+	}
+	mu.Unlock()
+}
+`,
+			want: map[string]string{"mutexLock": "1", "rwMutexLock": "1", "rwMutexUnlock": "1", "mutexUnlock": "0"},
+		},
+		{
+			name: "field and variable",
+			src: `package p
+
+import "sync"
+
+type S struct {
+	wg sync.WaitGroup
+}
+
+func f(s *S) {
+	var wg sync.Mutex
+	wg.Lock()
+	s.wg.Wait()
+}
+`,
+			want: map[string]string{"mutexLock": "1", "waitGroupWait": "1", "unknownWait": "0", "unknownLock": "0"},
+		},
+	}, Options{})
+}
+
+func TestProcessFile(t *testing.T) {
+	files := map[string]string{
+		"a.go": `package p
+
+func (s *S) f() {
+	s.mu.Lock()
+	mu.Lock()
+	wg.Wait()
+}
+`,
+		"b.go": `package p
+
+import "sync"
+
+type S struct {
+	mu sync.Mutex
+}
+`,
+		"b_test.go": `package p
+
+import "sync"
+
+var mu sync.RWMutex
+`,
+		"c.go": `//go:build ignore
+
+package main
+
+import "sync"
+
+var wg sync.WaitGroup
+`,
+	}
+	columns := analyzeFile(t, files, "a.go", Options{})
+	checkColumns(t, columns, map[string]string{"mutexLock": "1", "rwMutexLock": "0", "unknownLock": "1",
+		"waitGroupWait": "0", "unknownWait": "1"})
+}
+
+func TestModuleImports(t *testing.T) {
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"cache/cache.go": `package cache
+
+import "sync"
+
+type Inner struct {
+	sync.Mutex
+}
+`,
+		"store/store.go": `package store
+
+import (
+	"sync"
+
+	"example.com/m/cache"
+)
+
+type Cache struct {
+	sync.RWMutex
+}
+
+type Nested struct {
+	cache.Inner
+}
+
+var Default Cache
+`,
+		"main.go": `package main
+
+import (
+	"example.com/m/store"
+	db "example.com/m/store"
+)
+
+type S struct {
+	cache  store.Cache
+	nested db.Nested
+}
+
+func (s *S) f() {
+	s.cache.RLock()
+	s.nested.Lock()
+	store.Default.Lock()
+}
+`,
+	}
+	columns := analyzeFiles(t, files, Options{})["main.go"]
+	checkColumns(t, columns, map[string]string{"rwMutexRLock": "1", "mutexLock": "1", "rwMutexLock": "1",
+		"unknownLock": "0", "unknownRLock": "0", "rwMutexDecls": "0", "mutexDecls": "0"})
+}
+
+func TestAliases(t *testing.T) {
+	runCounterTests(t, []counterTest{
+		{
+			name: "pointers and copies",
+			src: `package p
+
+import "sync"
+
+type S struct {
+	mu sync.Mutex
+	wg *sync.WaitGroup
+}
+
+func (s *S) f() {
+	mu := &s.mu
+	mu.Lock()
+	wg := s.wg
+	wg.Done()
+	var m sync.Mutex
+	p := &m
+	p.Unlock()
+}
+`,
+			want: map[string]string{"mutexLock": "1", "waitGroupDone": "1", "mutexUnlock": "1", "unknownLock": "0",
+				"unknownDone": "0", "unknownUnlock": "0", "primAliases": "3", "aliasedUses": "3"},
+		},
+		{
+			name: "read side",
+			src: `package p
+
+import "sync"
+
+func f() {
+	var rw sync.RWMutex
+	l := rw.RLocker()
+	l.Lock()
+	l.Unlock()
+}
+`,
+			want: map[string]string{"rwMutexRLock": "1", "rwMutexRUnlock": "1", "rwMutexRLocker": "1",
+				"rwMutexLock": "0", "unknownLock": "0", "aliasedUses": "2"},
+		},
+		{
+			name: "method value",
+			src: `package p
+
+import "sync"
+
+type S struct {
+	mu sync.Mutex
+}
+
+func (s *S) f() {
+	s.mu.Lock()
+	unlock := s.mu.Unlock
+	defer unlock()
+}
+`,
+			want: map[string]string{"mutexLock": "1", "mutexUnlock": "1", "unknownUnlock": "0", "aliasedUses": "1"},
+		},
+		{
+			name: "bound parameter",
+			src: `package p
+
+import "sync"
+
+func with(l sync.Locker) {
+	l.Lock()
+	l.Unlock()
+}
+
+func f() {
+	var m sync.Mutex
+	with(&m)
+}
+`,
+			want: map[string]string{"mutexLock": "1", "mutexUnlock": "1", "lockerLock": "0", "primAliases": "1",
+				"aliasedUses": "2"},
+		},
+		{
+			name: "conflicting bindings",
+			src: `package p
+
+import "sync"
+
+func with(l sync.Locker) {
+	l.Lock()
+}
+
+func f() {
+	var m sync.RWMutex
+	with(&m)
+	with(m.RLocker())
+}
+`,
+			want: map[string]string{"lockerLock": "1", "rwMutexLock": "0", "rwMutexRLock": "0", "aliasedUses": "0"},
+		},
+		{
+			name: "cond parameter",
+			src: `package p
+
+import "sync"
+
+type Button struct {
+	Clicked *sync.Cond
+}
+
+func subscribe(c *sync.Cond) {
+	c.L.Lock()
+	c.L.Unlock()
+}
+
+func f() {
+	b := Button{Clicked: sync.NewCond(&sync.Mutex{})}
+	subscribe(b.Clicked)
+}
+`,
+			want: map[string]string{"condLock": "1", "condUnlock": "1", "mutexLock": "1", "mutexUnlock": "1"},
+		},
+	}, Options{})
+}